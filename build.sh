@@ -36,9 +36,15 @@ if [ $build_status -ne 0 ]; then
   exit 1
 fi
 
+# 密钥通过环境变量传入容器，不写入镜像；未设置的变量不会传入容器，
+# 是否缺少密钥由程序启动时按实际配置校验（例如只有使用腾讯云翻译时才需要 TENCENT_SECRET_ID），错误见 docker logs
+
 # 启动应用容器，连接到已存在的 Docker 网络
 echo "Running Docker container $IMAGE_NAME..."
-docker run -d -p 10086:8080 --name $IMAGE_NAME --network $NETWORK_NAME $IMAGE_NAME:$TAG
+# 停止容器时等待 30 秒（默认 10 秒），大于配置中的 shutdown_timeout，让进行中的推送完成
+docker run -d -p 10086:8080 --name $IMAGE_NAME --network $NETWORK_NAME --stop-timeout 30 \
+  -e LARK_WEBHOOK_URL -e LARK_SECRET -e TENCENT_SECRET_ID -e TENCENT_SECRET_KEY \
+  $IMAGE_NAME:$TAG
 
 # 检查容器是否成功启动
 if [ $? -ne 0 ]; then
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -10,26 +12,273 @@ import (
 // 配置文件结构
 type SiteConfig struct {
 	Name        string            `yaml:"name"`
+	Type        string            `yaml:"type"` // 站点类型：html（默认）、feed 或 json
 	BaseURL     string            `yaml:"base_url"`
 	RealURL     string            `yaml:"real_url"`
 	ParseRules  map[string]string `yaml:"parse_rules"`
+	Selectors   *SelectorRules    `yaml:"selectors"` // 配置后优先于 ParseRules
+	API         *APIConfig        `yaml:"api"`       // json 类型站点的接口配置
 	DateFormats []string          `yaml:"date_formats"`
+	Timezone    string            `yaml:"timezone"`  // 站点日期所在的时区（IANA 名称），默认 UTC
+	MaxAge      time.Duration     `yaml:"max_age"`   // 条目的最大时效，超过的条目会被丢弃，默认使用全局 max_age
+	Language    string            `yaml:"language"`  // 站点内容的语言，为空时按标题自动检测
+	Translate   *bool             `yaml:"translate"` // 是否翻译标题，默认翻译
+	Category    string            `yaml:"category"`  // 站点分类，例如 tech、pharma、macro、policy，决定飞书卡片标题栏的颜色
+	Tags        []string          `yaml:"tags"`      // 站点标签，用于推送路由，分类也视为一个标签
+	Filters     FilterConfig      `yaml:"filters"`   // 标题过滤规则，未通过的条目不推送
+	Interval    time.Duration     `yaml:"interval"`  // 抓取间隔，默认使用全局 interval
+	Cron        string            `yaml:"cron"`      // 抓取时间的 cron 表达式（分 时 日 月 周），配置后优先于 interval，按全局时区解释
+	Jitter      time.Duration     `yaml:"jitter"`    // 每次抓取时间的随机延迟上限，默认使用全局 jitter，负数表示不延迟
+
+	location *time.Location
+}
+
+// Location 返回站点日期所在的时区
+func (s SiteConfig) Location() *time.Location {
+	if s.location != nil {
+		return s.location
+	}
+	if loc, err := time.LoadLocation(s.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// TranslateEnabled 返回站点标题是否需要翻译
+func (s SiteConfig) TranslateEnabled() bool {
+	return s.Translate == nil || *s.Translate
+}
+
+// 站点类型
+const (
+	SiteTypeHTML = "html"
+	SiteTypeFeed = "feed" // RSS、Atom 或 JSON Feed
+	SiteTypeJSON = "json" // 返回 JSON 的列表接口
+)
+
+// APIConfig json 类型站点的接口请求与字段映射，字段使用 JSONPath 表达式
+type APIConfig struct {
+	URL     string            `yaml:"url"`     // 接口地址，为空时使用 base_url
+	Method  string            `yaml:"method"`  // 请求方法，默认 GET
+	Headers map[string]string `yaml:"headers"` // 额外的请求头
+	Body    string            `yaml:"body"`    // 请求体
+
+	Items string `yaml:"items"` // 条目列表，例如 $.data.items[*]
+	Title string `yaml:"title"` // 相对于单个条目的标题，例如 $.title
+	Link  string `yaml:"link"`  // 相对于单个条目的链接
+	Date  string `yaml:"date"`  // 相对于单个条目的日期，支持字符串或 Unix 时间戳
+}
+
+// 选择器类型
+const (
+	SelectorCSS   = "css"
+	SelectorXPath = "xpath"
+)
+
+// SelectorRules 基于 CSS 选择器或 XPath 的解析规则
+type SelectorRules struct {
+	Type  string        `yaml:"type"`  // css（默认）或 xpath
+	Item  string        `yaml:"item"`  // 每条新闻所在的元素
+	Title FieldSelector `yaml:"title"` // 标题
+	Link  FieldSelector `yaml:"link"`  // 链接，为空时取标题元素或其中 <a> 的 href
+	Date  FieldSelector `yaml:"date"`  // 日期
 }
 
+// 字段查找范围
+const (
+	ScopeItem     = "item"
+	ScopeDocument = "document"
+)
+
+// FieldSelector 单个字段的选择器，可直接写成字符串（只配置 selector）
+type FieldSelector struct {
+	Selector string `yaml:"selector"` // 相对于条目元素的选择器，为空表示条目元素本身
+	Attr     string `yaml:"attr"`     // 取值的属性名，为空时取元素文本
+	Scope    string `yaml:"scope"`    // item（默认）在条目内查找；document 在整个页面查找并按顺序与条目对应
+}
+
+// UnmarshalYAML 支持字段选择器直接写成字符串
+func (f *FieldSelector) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var selector string
+	if err := unmarshal(&selector); err == nil {
+		f.Selector = selector
+		return nil
+	}
+
+	type plain FieldSelector
+	return unmarshal((*plain)(f))
+}
+
+// TranslateConfig 标题翻译配置
+type TranslateConfig struct {
+	Provider   string            `yaml:"provider"`    // 翻译服务：tencent（默认）、dictionary 或 noop
+	TargetLang string            `yaml:"target_lang"` // 目标语言，默认 zh
+	Dictionary map[string]string `yaml:"dictionary"`  // dictionary 翻译服务使用的原文到译文的映射
+	CacheTTL   time.Duration     `yaml:"cache_ttl"`   // 译文缓存时间，默认 30 天
+}
+
+// DestinationConfig 推送目的地配置
+type DestinationConfig struct {
+	Name       string            `yaml:"name"`        // 目的地名称，用于日志和路由
+	Type       string            `yaml:"type"`        // lark、dingtalk、wecom、slack、telegram 或 webhook
+	WebhookURL string            `yaml:"webhook_url"` // 机器人或 webhook 地址
+	Secret     string            `yaml:"secret"`      // 加签（钉钉）或签名校验（飞书）密钥
+	BotToken   string            `yaml:"bot_token"`   // Telegram 机器人 token
+	ChatID     string            `yaml:"chat_id"`     // Telegram 会话 ID
+	APIURL     string            `yaml:"api_url"`     // Telegram Bot API 地址，默认官方地址
+	Headers    map[string]string `yaml:"headers"`     // 通用 webhook 的额外请求头
+	Timeout    time.Duration     `yaml:"timeout"`     // 推送请求超时，默认 10 秒
+	Format     string            `yaml:"format"`      // 飞书消息格式：card（默认，消息卡片）或 text
+	Colors     map[string]string `yaml:"colors"`      // 飞书卡片的分类到标题栏颜色的映射，覆盖内置配色
+	RateLimits []RateLimit       `yaml:"rate_limits"` // 推送频率限制，为空时使用渠道默认值（飞书为每秒 5 次且每分钟 100 次）
+}
+
+// FilterConfig 标题过滤规则，同时检查标题原文和译文；关键词不区分大小写，正则表达式可用 (?i) 忽略大小写
+type FilterConfig struct {
+	Include      []string `yaml:"include"`       // 标题包含其中任一关键词时才推送
+	Exclude      []string `yaml:"exclude"`       // 标题包含其中任一关键词时不推送，优先于 include
+	IncludeRegex []string `yaml:"include_regex"` // 标题匹配其中任一正则表达式时才推送
+	ExcludeRegex []string `yaml:"exclude_regex"` // 标题匹配其中任一正则表达式时不推送
+}
+
+// RouteConfig 推送路由规则：条目同时满足配置的各项条件时推送到 Destinations，未配置任何条件的规则匹配所有条目
+type RouteConfig struct {
+	Name         string       `yaml:"name"`         // 规则名称，用于日志
+	Sites        []string     `yaml:"sites"`        // 站点名称，匹配其中任一站点
+	Tags         []string     `yaml:"tags"`         // 站点标签或分类，匹配其中任一标签
+	Keywords     []string     `yaml:"keywords"`     // 标题原文或译文包含其中任一关键词（不区分大小写）
+	Filters      FilterConfig `yaml:"filters"`      // 标题过滤规则，与 keywords 同时满足
+	Destinations []string     `yaml:"destinations"` // 推送目的地名称
+}
+
+// RateLimit 推送频率限制：每 Per 时间内最多推送 Requests 次
+type RateLimit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+}
+
+// DeliveryConfig 推送重试队列配置
+type DeliveryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"`    // 最大推送次数，超过后移入死信列表，默认 8
+	InitialBackoff time.Duration `yaml:"initial_backoff"` // 首次重试的等待时间，之后每次翻倍，默认 30 秒
+	MaxBackoff     time.Duration `yaml:"max_backoff"`     // 重试等待时间上限，默认 1 小时
+	PollInterval   time.Duration `yaml:"poll_interval"`   // 检查待重试消息的间隔，默认 15 秒
+}
+
+// HealthConfig 站点失败追踪与熔断配置
+type HealthConfig struct {
+	FailureThreshold  int           `yaml:"failure_threshold"`  // 连续失败多少次后打开熔断器并告警，默认 5
+	MaxBackoff        time.Duration `yaml:"max_backoff"`        // 失败后抓取间隔的上限，熔断期间按该间隔探测，默认 1 小时
+	AlertDestinations []string      `yaml:"alert_destinations"` // 接收站点故障和恢复告警的推送目的地，为空时只记录日志
+}
+
+// RedisConfig Redis 连接配置
+type RedisConfig struct {
+	Addr     string `yaml:"addr"`     // Redis 服务器地址，默认 my-redis:6379
+	Password string `yaml:"password"` // Redis 密码
+	DB       int    `yaml:"db"`       // Redis 数据库编号
+}
 
 type TencentParamsConfig struct {
 	SecretID  string `yaml:"secret_id"`
 	SecretKey string `yaml:"secret_key"`
 }
 
+const (
+	// DefaultSeenRetention 是已推送条目指纹的默认保留时间
+	DefaultSeenRetention = 30 * 24 * time.Hour
+	// DefaultMaxAge 是条目的默认最大时效
+	DefaultMaxAge = 7 * 24 * time.Hour
+	// DefaultTranslateCacheTTL 是译文缓存的默认时间
+	DefaultTranslateCacheTTL = 30 * 24 * time.Hour
+	// DefaultTimezone 是推送消息中日期显示的默认时区
+	DefaultTimezone = "Asia/Shanghai"
+	// DefaultInterval 是站点的默认抓取间隔
+	DefaultInterval = 2 * time.Minute
+	// DefaultJitter 是每次抓取时间的默认随机延迟上限
+	DefaultJitter = 10 * time.Second
+	// DefaultConcurrency 是同时处理的站点数
+	DefaultConcurrency = 4
+	// DefaultTickTimeout 是每轮抓取等待所有站点完成的时间上限
+	DefaultTickTimeout = 2 * time.Minute
+	// DefaultShutdownTimeout 是退出时等待进行中任务完成的时间，需小于容器的停止等待时间
+	DefaultShutdownTimeout = 25 * time.Second
+	// DefaultFailureThreshold 是站点熔断前允许的连续失败次数
+	DefaultFailureThreshold = 5
+	// DefaultMaxSiteBackoff 是站点失败后抓取间隔的默认上限
+	DefaultMaxSiteBackoff = time.Hour
+	// DefaultMaxAttempts 是每条消息的默认最大推送次数
+	DefaultMaxAttempts = 8
+	// DefaultInitialBackoff 是首次重试的默认等待时间
+	DefaultInitialBackoff = 30 * time.Second
+	// DefaultMaxBackoff 是重试等待时间的默认上限
+	DefaultMaxBackoff = time.Hour
+	// DefaultPollInterval 是检查待重试消息的默认间隔
+	DefaultPollInterval = 15 * time.Second
+)
 
 type Config struct {
 	Sites []SiteConfig `yaml:"sites"`
 
-	TencentParams  TencentParamsConfig  `yaml:"tencent_params"`
+	// SeenRetention 已推送条目指纹的保留时间，条目离开列表页超过该时间后记录会被清理
+	SeenRetention time.Duration `yaml:"seen_retention"`
+
+	// Timezone 推送消息中日期显示的时区
+	Timezone string `yaml:"timezone"`
+
+	// MaxAge 条目的默认最大时效，站点未配置 max_age 时使用
+	MaxAge time.Duration `yaml:"max_age"`
+
+	// Interval 站点的默认抓取间隔
+	Interval time.Duration `yaml:"interval"`
+
+	// Jitter 每次抓取时间的随机延迟上限，避免所有站点同时抓取，负数表示不延迟
+	Jitter time.Duration `yaml:"jitter"`
+
+	// Concurrency 同时处理的站点数上限，同一域名的站点始终串行抓取
+	Concurrency int `yaml:"concurrency"`
+
+	// TickTimeout 每轮抓取等待所有站点完成的时间上限，超时的站点在后台继续执行，下一轮跳过
+	TickTimeout time.Duration `yaml:"tick_timeout"`
+
+	// ShutdownTimeout 收到退出信号后等待进行中的抓取和推送完成的时间，超时后中止
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Translate 标题翻译配置
+	Translate TranslateConfig `yaml:"translate"`
+
+	// Destinations 推送目的地
+	Destinations []DestinationConfig `yaml:"destinations"`
+
+	// Routes 推送路由规则，条目推送到所有匹配规则的目的地；未配置时推送到所有目的地
+	Routes []RouteConfig `yaml:"routes"`
+
+	// Delivery 推送重试队列配置
+	Delivery DeliveryConfig `yaml:"delivery"`
+
+	// Health 站点失败追踪与熔断配置
+	Health HealthConfig `yaml:"health"`
+
+	// Redis Redis 连接配置
+	Redis RedisConfig `yaml:"redis"`
+
+	// MetricsAddr 指标服务的监听地址，例如 :8080，指标通过 /debug/vars 暴露；为空时不启动
+	MetricsAddr string `yaml:"metrics_addr"`
+
+	TencentParams TencentParamsConfig `yaml:"tencent_params"`
+
+	location *time.Location
+}
+
+// Location 返回推送消息中日期显示的时区
+func (c *Config) Location() *time.Location {
+	if c.location != nil {
+		return c.location
+	}
+	return time.UTC
 }
 
-// LoadConfig 加载配置文件
+// LoadConfig 加载配置文件，替换其中的 ${ENV_VAR} 与 ${file:/path} 占位符，补全默认值并校验
 func LoadConfig(filename string) (*Config, error) {
 	file, err := os.ReadFile(filename)
 	if err != nil {
@@ -42,6 +291,86 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置文件失败: %v", err)
 	}
 
+	// 替换环境变量和密钥文件占位符，密钥不写在配置文件中
+	if err := expandStrings(reflect.ValueOf(&config), ""); err != nil {
+		return nil, fmt.Errorf("替换配置占位符失败: %v", err)
+	}
+
+	if config.SeenRetention <= 0 {
+		config.SeenRetention = DefaultSeenRetention
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultMaxAge
+	}
+	if config.Translate.TargetLang == "" {
+		config.Translate.TargetLang = "zh"
+	}
+	if config.Translate.CacheTTL <= 0 {
+		config.Translate.CacheTTL = DefaultTranslateCacheTTL
+	}
+	if config.Timezone == "" {
+		config.Timezone = DefaultTimezone
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Jitter < 0 {
+		config.Jitter = 0
+	} else if config.Jitter == 0 {
+		config.Jitter = DefaultJitter
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	if config.TickTimeout <= 0 {
+		config.TickTimeout = DefaultTickTimeout
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if config.Health.FailureThreshold <= 0 {
+		config.Health.FailureThreshold = DefaultFailureThreshold
+	}
+	if config.Health.MaxBackoff <= 0 {
+		config.Health.MaxBackoff = DefaultMaxSiteBackoff
+	}
+	if config.Delivery.MaxAttempts <= 0 {
+		config.Delivery.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.Delivery.InitialBackoff <= 0 {
+		config.Delivery.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.Delivery.MaxBackoff <= 0 {
+		config.Delivery.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.Delivery.PollInterval <= 0 {
+		config.Delivery.PollInterval = DefaultPollInterval
+	}
+	config.location, err = time.LoadLocation(config.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("时区 %q 无效: %v", config.Timezone, err)
+	}
+
+	for i := range config.Sites {
+		site := &config.Sites[i]
+		site.location, err = time.LoadLocation(site.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("站点 %s 的时区 %q 无效: %v", site.Name, site.Timezone, err)
+		}
+		if site.MaxAge <= 0 {
+			site.MaxAge = config.MaxAge
+		}
+		if site.Interval <= 0 {
+			site.Interval = config.Interval
+		}
+		if site.Jitter == 0 {
+			site.Jitter = config.Jitter
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
-
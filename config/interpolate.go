@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern 匹配 ${...} 占位符，$${...} 表示不做替换的字面量
+var placeholderPattern = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// expandValue 替换字符串中的占位符：
+//   - ${VAR}：环境变量 VAR，未设置时报错
+//   - ${VAR:-default}：环境变量 VAR，未设置或为空时使用 default
+//   - ${file:/run/secrets/name}：读取文件内容（去掉首尾空白），用于 Docker/Kubernetes secrets
+func expandValue(value string) (string, error) {
+	var firstErr error
+	expanded := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		resolved, err := resolvePlaceholder(match[2 : len(match)-1])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return resolved
+	})
+	return expanded, firstErr
+}
+
+// resolvePlaceholder 解析单个占位符的内容
+func resolvePlaceholder(expr string) (string, error) {
+	if path, ok := strings.CutPrefix(expr, "file:"); ok {
+		content, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return "", fmt.Errorf("读取密钥文件失败: %v", err)
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+
+	name, fallback, hasFallback := strings.Cut(expr, ":-")
+	value, ok := os.LookupEnv(name)
+	if hasFallback && value == "" {
+		return fallback, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", name)
+	}
+	return value, nil
+}
+
+// expandStrings 递归替换结构体中所有可导出字符串字段（包括切片、map 和指针中的字符串）里的占位符
+func expandStrings(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return expandStrings(v.Elem(), path)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := expandStrings(v.Field(i), joinPath(path, fieldName(t.Field(i)))); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := expandStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			expanded, err := expandValue(v.MapIndex(key).String())
+			if err != nil {
+				return fmt.Errorf("%s: %v", joinPath(path, fmt.Sprint(key.Interface())), err)
+			}
+			v.SetMapIndex(key, reflect.ValueOf(expanded).Convert(v.Type().Elem()))
+		}
+	case reflect.String:
+		expanded, err := expandValue(v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		v.SetString(expanded)
+	}
+	return nil
+}
+
+// fieldName 返回字段在配置文件中的名称
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// joinPath 拼接配置项路径，用于错误提示
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
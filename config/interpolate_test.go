@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandValue(t *testing.T) {
+	t.Setenv("NEWS_TEST_TOKEN", "tok-123")
+	t.Setenv("NEWS_TEST_EMPTY", "")
+	os.Unsetenv("NEWS_TEST_UNSET")
+
+	secret := filepath.Join(t.TempDir(), "lark_secret")
+	if err := os.WriteFile(secret, []byte("  s3cret\n"), 0o600); err != nil {
+		t.Fatalf("写入密钥文件: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string // 为空表示不出错
+	}{
+		{"没有占位符", "https://example.com/hook", "https://example.com/hook", ""},
+		{"环境变量", "${NEWS_TEST_TOKEN}", "tok-123", ""},
+		{"嵌在字符串中", "https://open.feishu.cn/hook/${NEWS_TEST_TOKEN}?a=1", "https://open.feishu.cn/hook/tok-123?a=1", ""},
+		{"多个占位符", "${NEWS_TEST_TOKEN}-${NEWS_TEST_TOKEN}", "tok-123-tok-123", ""},
+		{"已设置时忽略默认值", "${NEWS_TEST_TOKEN:-fallback}", "tok-123", ""},
+		{"未设置时使用默认值", "${NEWS_TEST_UNSET:-fallback}", "fallback", ""},
+		{"为空时使用默认值", "${NEWS_TEST_EMPTY:-fallback}", "fallback", ""},
+		{"默认值为空", "${NEWS_TEST_UNSET:-}", "", ""},
+		{"为空且没有默认值", "${NEWS_TEST_EMPTY}", "", ""},
+		{"$$ 转义", "$${NEWS_TEST_TOKEN}", "${NEWS_TEST_TOKEN}", ""},
+		{"$$ 转义与替换混用", "$${HOME}:${NEWS_TEST_TOKEN}", "${HOME}:tok-123", ""},
+		{"读取文件并去掉首尾空白", "${file:" + secret + "}", "s3cret", ""},
+		{"未设置的环境变量", "${NEWS_TEST_UNSET}", "", "环境变量 NEWS_TEST_UNSET 未设置"},
+		{"文件不存在", "${file:/nonexistent/secret}", "", "读取密钥文件失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandValue(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expandValue(%q) 错误 = %v, 期望包含 %q", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandValue(%q): %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("expandValue(%q) = %q, 期望 %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandStrings(t *testing.T) {
+	t.Setenv("NEWS_TEST_TOKEN", "tok-123")
+	os.Unsetenv("NEWS_TEST_UNSET")
+
+	cfg := Config{
+		Destinations: []DestinationConfig{{
+			WebhookURL: "https://example.com/${NEWS_TEST_TOKEN}",
+			Headers:    map[string]string{"Authorization": "Bearer ${NEWS_TEST_TOKEN}"},
+		}},
+	}
+	if err := expandStrings(reflect.ValueOf(&cfg), ""); err != nil {
+		t.Fatalf("expandStrings: %v", err)
+	}
+	if got := cfg.Destinations[0].WebhookURL; got != "https://example.com/tok-123" {
+		t.Errorf("webhook_url = %q", got)
+	}
+	if got := cfg.Destinations[0].Headers["Authorization"]; got != "Bearer tok-123" {
+		t.Errorf("headers.Authorization = %q", got)
+	}
+
+	cfg.Destinations[0].BotToken = "${NEWS_TEST_UNSET}"
+	err := expandStrings(reflect.ValueOf(&cfg), "")
+	if err == nil || !strings.Contains(err.Error(), "destinations[0].bot_token") {
+		t.Errorf("expandStrings 错误 = %v, 期望包含配置项路径 destinations[0].bot_token", err)
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// destinationRequiredFields 列出各推送渠道类型必须配置的字段
+var destinationRequiredFields = map[string][]string{
+	"lark":     {"webhook_url"},
+	"dingtalk": {"webhook_url"},
+	"wecom":    {"webhook_url"},
+	"slack":    {"webhook_url"},
+	"telegram": {"bot_token", "chat_id"},
+	"webhook":  {"webhook_url"},
+}
+
+// Validate 检查配置是否完整有效，返回所有问题的汇总
+func (c *Config) Validate() error {
+	var problems []string
+
+	siteNames := make(map[string]bool)
+	for i, site := range c.Sites {
+		prefix := fmt.Sprintf("sites[%d] %s", i, site.Name)
+		if site.Name == "" {
+			problems = append(problems, prefix+": 缺少 name")
+		} else if siteNames[site.Name] {
+			problems = append(problems, prefix+": 站点名称重复")
+		}
+		siteNames[site.Name] = true
+
+		problems = append(problems, validateFilters(prefix, site.Filters)...)
+		if site.Cron != "" {
+			if _, err := cron.ParseStandard(site.Cron); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: cron 表达式 %q 无效: %v", prefix, site.Cron, err))
+			}
+		}
+
+		switch site.Type {
+		case "", SiteTypeHTML:
+			if site.BaseURL == "" {
+				problems = append(problems, prefix+": 缺少 base_url")
+			}
+			if site.Selectors == nil && site.ParseRules["content"] == "" {
+				problems = append(problems, prefix+": 缺少 selectors 或 parse_rules.content")
+			}
+			if site.Selectors != nil {
+				problems = append(problems, validateSelectors(prefix, site.Selectors)...)
+			}
+		case SiteTypeFeed:
+			if site.BaseURL == "" {
+				problems = append(problems, prefix+": 缺少 base_url")
+			}
+		case SiteTypeJSON:
+			if site.API == nil {
+				problems = append(problems, prefix+": json 类型站点缺少 api")
+			} else {
+				if site.BaseURL == "" && site.API.URL == "" {
+					problems = append(problems, prefix+": 缺少 base_url 或 api.url")
+				}
+				if site.API.Items == "" || site.API.Title == "" || site.API.Link == "" {
+					problems = append(problems, prefix+": api 缺少 items、title 或 link")
+				}
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s: 不支持的站点类型 %s", prefix, site.Type))
+		}
+	}
+
+	if len(c.Destinations) == 0 {
+		problems = append(problems, "destinations: 至少需要配置一个推送目的地")
+	}
+	destinationNames := make(map[string]bool)
+	for i, destination := range c.Destinations {
+		prefix := fmt.Sprintf("destinations[%d] %s", i, destination.Name)
+		if destination.Name == "" {
+			problems = append(problems, prefix+": 缺少 name")
+		} else if destinationNames[destination.Name] {
+			problems = append(problems, prefix+": 目的地名称重复")
+		}
+		destinationNames[destination.Name] = true
+
+		required, ok := destinationRequiredFields[destination.Type]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: 不支持的推送渠道类型 %s", prefix, destination.Type))
+			continue
+		}
+		values := map[string]string{
+			"webhook_url": destination.WebhookURL,
+			"bot_token":   destination.BotToken,
+			"chat_id":     destination.ChatID,
+		}
+		for _, field := range required {
+			if values[field] == "" {
+				problems = append(problems, fmt.Sprintf("%s: 缺少 %s", prefix, field))
+			}
+		}
+		switch destination.Format {
+		case "", "card", "text":
+		default:
+			problems = append(problems, fmt.Sprintf("%s: 不支持的消息格式 %s", prefix, destination.Format))
+		}
+		for j, limit := range destination.RateLimits {
+			if limit.Requests <= 0 || limit.Per <= 0 {
+				problems = append(problems, fmt.Sprintf("%s: rate_limits[%d] 的 requests 和 per 必须大于 0", prefix, j))
+			}
+		}
+	}
+
+	for i, route := range c.Routes {
+		prefix := fmt.Sprintf("routes[%d] %s", i, route.Name)
+		if len(route.Destinations) == 0 {
+			problems = append(problems, prefix+": 缺少 destinations")
+		}
+		for _, name := range route.Destinations {
+			if !destinationNames[name] {
+				problems = append(problems, fmt.Sprintf("%s: 推送目的地 %s 不存在", prefix, name))
+			}
+		}
+		problems = append(problems, validateFilters(prefix, route.Filters)...)
+		for _, name := range route.Sites {
+			if !siteNames[name] {
+				problems = append(problems, fmt.Sprintf("%s: 站点 %s 不存在", prefix, name))
+			}
+		}
+	}
+
+	for _, name := range c.Health.AlertDestinations {
+		if !destinationNames[name] {
+			problems = append(problems, fmt.Sprintf("health: 告警目的地 %s 不存在", name))
+		}
+	}
+
+	switch c.Translate.Provider {
+	case "", "tencent":
+		if c.TencentParams.SecretID == "" || c.TencentParams.SecretKey == "" {
+			problems = append(problems, "tencent_params: 使用腾讯云翻译时需要 secret_id 和 secret_key")
+		}
+	case "dictionary", "noop":
+	default:
+		problems = append(problems, fmt.Sprintf("translate: 不支持的翻译服务 %s", c.Translate.Provider))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("配置校验失败:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// validateSelectors 检查选择器类型和字段的查找范围
+func validateSelectors(prefix string, selectors *SelectorRules) []string {
+	var problems []string
+	if selectors.Item == "" {
+		problems = append(problems, prefix+": 缺少 selectors.item")
+	}
+	switch selectors.Type {
+	case "", SelectorCSS, SelectorXPath:
+	default:
+		problems = append(problems, fmt.Sprintf("%s: 不支持的选择器类型 %s", prefix, selectors.Type))
+	}
+	fields := []struct {
+		name  string
+		field FieldSelector
+	}{
+		{"title", selectors.Title},
+		{"link", selectors.Link},
+		{"date", selectors.Date},
+	}
+	for _, f := range fields {
+		switch f.field.Scope {
+		case "", ScopeItem, ScopeDocument:
+		default:
+			problems = append(problems, fmt.Sprintf("%s: selectors.%s 不支持的查找范围 %s", prefix, f.name, f.field.Scope))
+		}
+	}
+	return problems
+}
+
+// validateFilters 检查过滤规则中的正则表达式能否编译
+func validateFilters(prefix string, filters FilterConfig) []string {
+	var problems []string
+	for _, patterns := range [][]string{filters.IncludeRegex, filters.ExcludeRegex} {
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: 正则表达式 %q 无效: %v", prefix, pattern, err))
+			}
+		}
+	}
+	return problems
+}
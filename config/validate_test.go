@@ -0,0 +1,41 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSelectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors SelectorRules
+		wantErr   string // 为空表示校验通过
+	}{
+		{"默认查找范围", SelectorRules{Item: "li", Title: FieldSelector{Selector: "a"}}, ""},
+		{"item 和 document", SelectorRules{Type: SelectorXPath, Item: "//li", Title: FieldSelector{Scope: ScopeItem}, Date: FieldSelector{Selector: "//time", Scope: ScopeDocument}}, ""},
+		{"缺少条目选择器", SelectorRules{}, "缺少 selectors.item"},
+		{"不支持的查找范围", SelectorRules{Item: "li", Date: FieldSelector{Selector: ".date", Scope: "page"}}, "selectors.date 不支持的查找范围 page"},
+		{"查找范围大小写敏感", SelectorRules{Item: "li", Link: FieldSelector{Scope: "Document"}}, "selectors.link 不支持的查找范围 Document"},
+		{"不支持的选择器类型", SelectorRules{Type: "jquery", Item: "li"}, "不支持的选择器类型 jquery"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectors := tt.selectors
+			cfg := Config{
+				Sites:        []SiteConfig{{Name: "site", BaseURL: "https://example.com", Selectors: &selectors}},
+				Destinations: []DestinationConfig{{Name: "lark", Type: "lark", WebhookURL: "https://example.com/hook"}},
+				Translate:    TranslateConfig{Provider: "noop"},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, 期望通过", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, 期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package dbtest 提供测试用的内存数据库实现
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"code/db"
+)
+
+// Memory 是 db.DatabaseClient 的内存实现，行为与 RedisClient 一致，仅供测试使用
+type Memory struct {
+	mu          sync.Mutex
+	values      map[string]string
+	seen        map[string]map[string]time.Time        // 站点到指纹记录时间的映射
+	queues      map[string]map[string]db.QueuedMessage // 队列到消息的映射
+	deadLetters map[string]map[string]string           // 队列到死信内容的映射
+}
+
+var _ db.DatabaseClient = (*Memory)(nil)
+
+// NewMemory 创建一个空的内存数据库
+func NewMemory() *Memory {
+	return &Memory{
+		values:      make(map[string]string),
+		seen:        make(map[string]map[string]time.Time),
+		queues:      make(map[string]map[string]db.QueuedMessage),
+		deadLetters: make(map[string]map[string]string),
+	}
+}
+
+// 实现 DatabaseClient 接口的 SetKey 方法
+func (m *Memory) SetKey(ctx context.Context, key string, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 GetKey 方法
+func (m *Memory) GetKey(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return "", errors.New("获取键值失败: 键不存在")
+	}
+	return value, nil
+}
+
+// 实现 DatabaseClient 接口的 DeleteKey 方法
+func (m *Memory) DeleteKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 SetKeyWithTTL 方法，不模拟过期
+func (m *Memory) SetKeyWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return m.SetKey(ctx, key, value)
+}
+
+// 实现 DatabaseClient 接口的 IsSeen 方法
+func (m *Memory) IsSeen(ctx context.Context, site string, fingerprint string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.seen[site][fingerprint]
+	return ok, nil
+}
+
+// 实现 DatabaseClient 接口的 MarkSeen 方法
+func (m *Memory) MarkSeen(ctx context.Context, site string, fingerprint string, retention time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if m.seen[site] == nil {
+		m.seen[site] = make(map[string]time.Time)
+	}
+	m.seen[site][fingerprint] = now
+	if retention > 0 {
+		for fp, at := range m.seen[site] {
+			if at.Before(now.Add(-retention)) {
+				delete(m.seen[site], fp)
+			}
+		}
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 TouchSeen 方法
+func (m *Memory) TouchSeen(ctx context.Context, site string, fingerprints []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, fingerprint := range fingerprints {
+		if _, ok := m.seen[site][fingerprint]; ok {
+			m.seen[site][fingerprint] = now
+		}
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 CountSeen 方法
+func (m *Memory) CountSeen(ctx context.Context, site string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.seen[site])), nil
+}
+
+// Seen 返回站点已见集合中的全部指纹，按字典序排序
+func (m *Memory) Seen(site string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fingerprints := make([]string, 0, len(m.seen[site]))
+	for fingerprint := range m.seen[site] {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+	return fingerprints
+}
+
+// 实现 DatabaseClient 接口的 Enqueue 方法，处理时间按毫秒截断，与 Redis 中的分数一致
+func (m *Memory) Enqueue(ctx context.Context, queue string, id string, payload string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.queues[queue] == nil {
+		m.queues[queue] = make(map[string]db.QueuedMessage)
+	}
+	m.queues[queue][id] = db.QueuedMessage{ID: id, Payload: payload, At: time.UnixMilli(at.UnixMilli())}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 DueMessages 方法，处理时间相同的消息按 ID 排序
+func (m *Memory) DueMessages(ctx context.Context, queue string, now time.Time, offset int64, limit int64) ([]db.QueuedMessage, error) {
+	messages := m.Queue(queue)
+	var due []db.QueuedMessage
+	for _, message := range messages {
+		if message.At.UnixMilli() <= now.UnixMilli() {
+			due = append(due, message)
+		}
+	}
+	if offset >= int64(len(due)) {
+		return nil, nil
+	}
+	due = due[offset:]
+	if limit > 0 && limit < int64(len(due)) {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// Queue 返回队列中的全部消息，按处理时间排序
+func (m *Memory) Queue(queue string) []db.QueuedMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := make([]db.QueuedMessage, 0, len(m.queues[queue]))
+	for _, message := range m.queues[queue] {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].At.Equal(messages[j].At) {
+			return messages[i].At.Before(messages[j].At)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+	return messages
+}
+
+// 实现 DatabaseClient 接口的 Dequeue 方法
+func (m *Memory) Dequeue(ctx context.Context, queue string, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.queues[queue], id)
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 MoveToDeadLetter 方法
+func (m *Memory) MoveToDeadLetter(ctx context.Context, queue string, id string, payload string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.queues[queue], id)
+	if m.deadLetters[queue] == nil {
+		m.deadLetters[queue] = make(map[string]string)
+	}
+	m.deadLetters[queue][id] = payload
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 DeadLetters 方法，按 ID 排序返回
+func (m *Memory) DeadLetters(ctx context.Context, queue string) ([]db.QueuedMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	messages := make([]db.QueuedMessage, 0, len(m.deadLetters[queue]))
+	for id, payload := range m.deadLetters[queue] {
+		messages = append(messages, db.QueuedMessage{ID: id, Payload: payload})
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// 实现 DatabaseClient 接口的 RemoveDeadLetter 方法
+func (m *Memory) RemoveDeadLetter(ctx context.Context, queue string, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.deadLetters[queue], id)
+	return nil
+}
+
+// 实现 Ping 方法
+func (m *Memory) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FetchState 是站点上次成功处理的响应的缓存校验信息，以 JSON 保存在数据库中
+type FetchState struct {
+	URL          string    `json:"url"`                     // 抓取地址，站点地址变更后旧记录失效
+	ETag         string    `json:"etag,omitempty"`          // 上次响应的 ETag
+	LastModified string    `json:"last_modified,omitempty"` // 上次响应的 Last-Modified
+	Hash         string    `json:"hash,omitempty"`          // 上次响应内容的哈希
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// fetchStateKey 返回站点抓取状态在数据库中的键名
+func fetchStateKey(site string) string {
+	return "fetch:" + site
+}
+
+// LoadFetchState 读取站点的抓取状态，没有记录或记录的地址与 url 不一致时返回零值
+func LoadFetchState(ctx context.Context, client DatabaseClient, site string, url string) FetchState {
+	var state FetchState
+	value, err := client.GetKey(ctx, fetchStateKey(site))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal([]byte(value), &state); err != nil || state.URL != url {
+		return FetchState{}
+	}
+	return state
+}
+
+// SaveFetchState 保存站点的抓取状态
+func SaveFetchState(ctx context.Context, client DatabaseClient, site string, state FetchState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("抓取状态序列化失败: %v", err)
+	}
+	return client.SetKey(ctx, fetchStateKey(site), string(value))
+}
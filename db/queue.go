@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// QueuedMessage 是延迟队列或死信列表中的一条消息
+type QueuedMessage struct {
+	ID      string
+	Payload string
+	At      time.Time // 最早处理时间，死信列表中为零值
+}
+
+// queueKey 返回延迟队列在 Redis 中的键名，队列使用有序集合存储消息 ID，分数为最早处理时间（毫秒）
+func queueKey(queue string) string {
+	return "queue:" + queue
+}
+
+// queuePayloadKey 返回延迟队列消息内容在 Redis 中的键名，使用哈希表按 ID 存储
+func queuePayloadKey(queue string) string {
+	return "queue:" + queue + ":payload"
+}
+
+// deadLetterKey 返回死信列表在 Redis 中的键名，使用哈希表按 ID 存储
+func deadLetterKey(queue string) string {
+	return "deadletter:" + queue
+}
+
+// 实现 DatabaseClient 接口的 Enqueue 方法
+func (r *RedisClient) Enqueue(ctx context.Context, queue string, id string, payload string, at time.Time) error {
+	pipe := r.Client.TxPipeline()
+	pipe.HSet(ctx, queuePayloadKey(queue), id, payload)
+	pipe.ZAdd(ctx, queueKey(queue), &redis.Z{Score: float64(at.UnixMilli()), Member: id})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入队列失败: %v", err)
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 DueMessages 方法
+func (r *RedisClient) DueMessages(ctx context.Context, queue string, now time.Time, offset int64, limit int64) ([]QueuedMessage, error) {
+	entries, err := r.Client.ZRangeByScoreWithScores(ctx, queueKey(queue), &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    strconv.FormatInt(now.UnixMilli(), 10),
+		Offset: offset,
+		Count:  limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取队列失败: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.Member.(string)
+	}
+	payloads, err := r.Client.HMGet(ctx, queuePayloadKey(queue), ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取队列消息失败: %v", err)
+	}
+
+	messages := make([]QueuedMessage, 0, len(entries))
+	for i, entry := range entries {
+		payload, ok := payloads[i].(string)
+		if !ok {
+			// 消息内容已被删除，清理残留的 ID
+			r.Client.ZRem(ctx, queueKey(queue), ids[i])
+			continue
+		}
+		messages = append(messages, QueuedMessage{
+			ID:      ids[i],
+			Payload: payload,
+			At:      time.UnixMilli(int64(entry.Score)),
+		})
+	}
+	return messages, nil
+}
+
+// 实现 DatabaseClient 接口的 Dequeue 方法
+func (r *RedisClient) Dequeue(ctx context.Context, queue string, id string) error {
+	pipe := r.Client.TxPipeline()
+	pipe.ZRem(ctx, queueKey(queue), id)
+	pipe.HDel(ctx, queuePayloadKey(queue), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("删除队列消息失败: %v", err)
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 MoveToDeadLetter 方法
+func (r *RedisClient) MoveToDeadLetter(ctx context.Context, queue string, id string, payload string) error {
+	pipe := r.Client.TxPipeline()
+	pipe.ZRem(ctx, queueKey(queue), id)
+	pipe.HDel(ctx, queuePayloadKey(queue), id)
+	pipe.HSet(ctx, deadLetterKey(queue), id, payload)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入死信列表失败: %v", err)
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 DeadLetters 方法，按 ID 排序返回
+func (r *RedisClient) DeadLetters(ctx context.Context, queue string) ([]QueuedMessage, error) {
+	values, err := r.Client.HGetAll(ctx, deadLetterKey(queue)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取死信列表失败: %v", err)
+	}
+	messages := make([]QueuedMessage, 0, len(values))
+	for id, payload := range values {
+		messages = append(messages, QueuedMessage{ID: id, Payload: payload})
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// 实现 DatabaseClient 接口的 RemoveDeadLetter 方法
+func (r *RedisClient) RemoveDeadLetter(ctx context.Context, queue string, id string) error {
+	if err := r.Client.HDel(ctx, deadLetterKey(queue), id).Err(); err != nil {
+		return fmt.Errorf("删除死信失败: %v", err)
+	}
+	return nil
+}
@@ -13,13 +13,49 @@ import (
 // DatabaseClient 是通用的数据库接口
 type DatabaseClient interface {
 	// SetKey 设置键值
-	SetKey(key string, value string) error
+	SetKey(ctx context.Context, key string, value string) error
 
 	// GetKey 获取键值
-	GetKey(key string) (string, error)
+	GetKey(ctx context.Context, key string) (string, error)
+
+	// DeleteKey 删除键
+	DeleteKey(ctx context.Context, key string) error
+
+	// SetKeyWithTTL 设置带过期时间的键值
+	SetKeyWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error
+
+	// IsSeen 判断条目指纹是否已在站点的已见集合中
+	IsSeen(ctx context.Context, site string, fingerprint string) (bool, error)
+
+	// MarkSeen 将条目指纹加入站点的已见集合，并清理超过保留期的记录
+	MarkSeen(ctx context.Context, site string, fingerprint string, retention time.Duration) error
+
+	// TouchSeen 将已在集合中的指纹的记录时间更新为当前时间，不在集合中的指纹会被忽略
+	TouchSeen(ctx context.Context, site string, fingerprints []string) error
+
+	// CountSeen 返回站点已见集合中的记录数
+	CountSeen(ctx context.Context, site string) (int64, error)
+
+	// Enqueue 将消息加入延迟队列，at 之后才会被取出；ID 相同的消息会被覆盖
+	Enqueue(ctx context.Context, queue string, id string, payload string, at time.Time) error
+
+	// DueMessages 按处理时间顺序返回队列中处理时间不晚于 now 的消息，跳过前 offset 条，最多 limit 条
+	DueMessages(ctx context.Context, queue string, now time.Time, offset int64, limit int64) ([]QueuedMessage, error)
+
+	// Dequeue 从延迟队列中删除消息
+	Dequeue(ctx context.Context, queue string, id string) error
+
+	// MoveToDeadLetter 将消息从延迟队列移到死信列表，payload 为更新后的消息内容
+	MoveToDeadLetter(ctx context.Context, queue string, id string, payload string) error
+
+	// DeadLetters 返回死信列表中的全部消息
+	DeadLetters(ctx context.Context, queue string) ([]QueuedMessage, error)
+
+	// RemoveDeadLetter 从死信列表中删除消息
+	RemoveDeadLetter(ctx context.Context, queue string, id string) error
 
 	// Ping 测试数据库连接
-	Ping() error
+	Ping(ctx context.Context) error
 }
 
 // DatabaseType 定义了支持的数据库类型
@@ -31,10 +67,10 @@ const (
 )
 
 // NewDatabaseClient 根据数据库类型返回相应的 DatabaseClient 实现
-func NewDatabaseClient(dbType DatabaseType) (DatabaseClient, error) {
+func NewDatabaseClient(dbType DatabaseType, redisParam *RedisParam) (DatabaseClient, error) {
 	switch dbType {
 	case RedisType:
-		return NewRedisClient()
+		return NewRedisClient(redisParam)
 	// 添加更多数据库的实现
 	default:
 		return nil, errors.New("unsupported database type")
@@ -44,7 +80,6 @@ func NewDatabaseClient(dbType DatabaseType) (DatabaseClient, error) {
 // RedisClient 是 Redis 数据库的客户端实现
 type RedisClient struct {
 	Client *redis.Client
-	Ctx    context.Context
 }
 
 // RedisParam 存储 Redis 连接的配置
@@ -55,19 +90,31 @@ type RedisParam struct {
 	Timeout  time.Duration // 连接和操作的超时
 }
 
-// 初始化 Redis 配置
-func initRedisParam() *RedisParam {
-	return &RedisParam{
+// 初始化 Redis 配置，未设置的字段使用默认值
+func initRedisParam(param *RedisParam) *RedisParam {
+	redisParam := &RedisParam{
 		Addr:     "my-redis:6379",
 		Password: "",              // Redis 密码
 		DB:       0,               // 默认数据库
 		Timeout:  5 * time.Second, // 默认超时 5 秒
 	}
+	if param == nil {
+		return redisParam
+	}
+	if param.Addr != "" {
+		redisParam.Addr = param.Addr
+	}
+	if param.Timeout > 0 {
+		redisParam.Timeout = param.Timeout
+	}
+	redisParam.Password = param.Password
+	redisParam.DB = param.DB
+	return redisParam
 }
 
-// NewRedisClient 创建一个新的 Redis 客户端
-func NewRedisClient() (*RedisClient, error) {
-	redisParam := initRedisParam()
+// NewRedisClient 创建一个新的 Redis 客户端，param 为 nil 时使用默认配置
+func NewRedisClient(param *RedisParam) (*RedisClient, error) {
+	redisParam := initRedisParam(param)
 	client := redis.NewClient(&redis.Options{
 		Addr:         redisParam.Addr,
 		Password:     redisParam.Password,
@@ -87,13 +134,21 @@ func NewRedisClient() (*RedisClient, error) {
 
 	return &RedisClient{
 		Client: client,
-		Ctx:    context.Background(),
 	}, nil
 }
 
 // 实现 DatabaseClient 接口的 SetKey 方法
-func (r *RedisClient) SetKey(key string, value string) error {
-	err := r.Client.Set(r.Ctx, key, value, 0).Err()
+func (r *RedisClient) SetKey(ctx context.Context, key string, value string) error {
+	err := r.Client.Set(ctx, key, value, 0).Err()
+	if err != nil {
+		return fmt.Errorf("设置键值失败: %v", err)
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 SetKeyWithTTL 方法
+func (r *RedisClient) SetKeyWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	err := r.Client.Set(ctx, key, value, ttl).Err()
 	if err != nil {
 		return fmt.Errorf("设置键值失败: %v", err)
 	}
@@ -101,17 +156,88 @@ func (r *RedisClient) SetKey(key string, value string) error {
 }
 
 // 实现 DatabaseClient 接口的 GetKey 方法
-func (r *RedisClient) GetKey(key string) (string, error) {
-	val, err := r.Client.Get(r.Ctx, key).Result()
+func (r *RedisClient) GetKey(ctx context.Context, key string) (string, error) {
+	val, err := r.Client.Get(ctx, key).Result()
 	if err != nil {
 		return "", fmt.Errorf("获取键值失败: %v", err)
 	}
 	return val, nil
 }
 
+// 实现 DatabaseClient 接口的 DeleteKey 方法
+func (r *RedisClient) DeleteKey(ctx context.Context, key string) error {
+	err := r.Client.Del(ctx, key).Err()
+	if err != nil {
+		return fmt.Errorf("删除键失败: %v", err)
+	}
+	return nil
+}
+
+// seenKey 返回站点已见集合在 Redis 中的键名
+func seenKey(site string) string {
+	return "seen:" + site
+}
+
+// 实现 DatabaseClient 接口的 IsSeen 方法
+func (r *RedisClient) IsSeen(ctx context.Context, site string, fingerprint string) (bool, error) {
+	_, err := r.Client.ZScore(ctx, seenKey(site), fingerprint).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("查询已见集合失败: %v", err)
+	}
+	return true, nil
+}
+
+// 实现 DatabaseClient 接口的 MarkSeen 方法
+// 已见集合使用有序集合存储，分数为记录时间，超过保留期的记录会被清理；
+// 集合本身不设置过期时间，新增记录与清理在同一事务中完成，集合不会因站点长期没有更新而被清空
+func (r *RedisClient) MarkSeen(ctx context.Context, site string, fingerprint string, retention time.Duration) error {
+	key := seenKey(site)
+	now := time.Now()
+
+	pipe := r.Client.TxPipeline()
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.Unix()), Member: fingerprint})
+	if retention > 0 {
+		cutoff := now.Add(-retention).Unix()
+		pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("(%d", cutoff))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("写入已见集合失败: %v", err)
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 TouchSeen 方法
+// 仍在列表页上的条目每次抓取都会刷新记录时间，保留期只清理已经离开页面的条目
+func (r *RedisClient) TouchSeen(ctx context.Context, site string, fingerprints []string) error {
+	if len(fingerprints) == 0 {
+		return nil
+	}
+	score := float64(time.Now().Unix())
+	members := make([]*redis.Z, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		members = append(members, &redis.Z{Score: score, Member: fingerprint})
+	}
+	if err := r.Client.ZAddXX(ctx, seenKey(site), members...).Err(); err != nil {
+		return fmt.Errorf("更新已见集合失败: %v", err)
+	}
+	return nil
+}
+
+// 实现 DatabaseClient 接口的 CountSeen 方法
+func (r *RedisClient) CountSeen(ctx context.Context, site string) (int64, error) {
+	count, err := r.Client.ZCard(ctx, seenKey(site)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("查询已见集合失败: %v", err)
+	}
+	return count, nil
+}
+
 // 实现 Ping 方法，测试数据库连接
-func (r *RedisClient) Ping() error {
-	_, err := r.Client.Ping(r.Ctx).Result()
+func (r *RedisClient) Ping(ctx context.Context) error {
+	_, err := r.Client.Ping(ctx).Result()
 	if err != nil {
 		return fmt.Errorf("redis Ping 失败: %v", err)
 	}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams 是规范化 URL 时需要去除的跟踪参数
+var trackingParams = map[string]bool{
+	"spm":     true,
+	"from":    true,
+	"fbclid":  true,
+	"gclid":   true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref":     true,
+	"ref_src": true,
+}
+
+// Fingerprint 根据规范化后的 URL 和标题哈希生成条目指纹
+func Fingerprint(rawURL, title string) string {
+	return NormalizeURL(rawURL) + "#" + titleHash(title)
+}
+
+// NormalizeURL 规范化 URL：忽略协议和默认端口，主机名小写，去掉锚点、跟踪参数和末尾的斜杠，并对查询参数排序
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return rawURL
+	}
+
+	host := strings.ToLower(parsedURL.Hostname())
+	if port := parsedURL.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := parsedURL.EscapedPath()
+	if path != "/" {
+		path = strings.TrimRight(path, "/")
+	}
+
+	query := parsedURL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	normalized := host + path
+	if len(params) > 0 {
+		normalized += "?" + strings.Join(params, "&")
+	}
+	return normalized
+}
+
+// titleHash 对去除多余空白后的标题计算哈希
+func titleHash(title string) string {
+	sum := sha1.Sum([]byte(strings.Join(strings.Fields(title), " ")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,58 @@
+package db
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"忽略协议", "http://example.com/news/1", "example.com/news/1"},
+		{"https", "https://example.com/news/1", "example.com/news/1"},
+		{"主机名小写", "https://Example.COM/News/1", "example.com/News/1"},
+		{"去掉 http 默认端口", "http://example.com:80/news/1", "example.com/news/1"},
+		{"去掉 https 默认端口", "https://example.com:443/news/1", "example.com/news/1"},
+		{"保留其他端口", "https://example.com:8080/news/1", "example.com:8080/news/1"},
+		{"去掉 utm 参数", "https://example.com/news/1?utm_source=x&UTM_Medium=y", "example.com/news/1"},
+		{"去掉跟踪参数", "https://example.com/news/1?id=3&spm=a.b&fbclid=z&from=timeline", "example.com/news/1?id=3"},
+		{"去掉末尾斜杠", "https://example.com/news/1/", "example.com/news/1"},
+		{"根路径保留斜杠", "https://example.com/", "example.com/"},
+		{"去掉锚点", "https://example.com/news/1#comments", "example.com/news/1"},
+		{"查询参数排序", "https://example.com/news?b=2&a=1&a=0", "example.com/news?a=0&a=1&b=2"},
+		{"去除首尾空白", "  https://example.com/news/1 \n", "example.com/news/1"},
+		{"相对地址原样返回", "/news/1", "/news/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeURL(tt.url); got != tt.want {
+				t.Errorf("NormalizeURL(%q) = %q, 期望 %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint("https://example.com/news/1", "NVIDIA announces new GPU")
+
+	tests := []struct {
+		name  string
+		url   string
+		title string
+		same  bool
+	}{
+		{"URL 规范化后相同", "http://EXAMPLE.com/news/1/?utm_source=rss", "NVIDIA announces new GPU", true},
+		{"标题空白不同", "https://example.com/news/1", "  NVIDIA  announces\nnew GPU ", true},
+		{"标题不同", "https://example.com/news/1", "NVIDIA announces new CPU", false},
+		{"URL 不同", "https://example.com/news/2", "NVIDIA announces new GPU", false},
+		{"查询参数不同", "https://example.com/news/1?page=2", "NVIDIA announces new GPU", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fingerprint(tt.url, tt.title)
+			if (got == base) != tt.same {
+				t.Errorf("Fingerprint(%q, %q) = %q, 与 %q 相同 = %v, 期望 %v", tt.url, tt.title, got, base, got == base, tt.same)
+			}
+		})
+	}
+}
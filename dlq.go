@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"code/db"
+	"code/outbox"
+)
+
+// dlqUsage 是 dlq 子命令的用法说明
+const dlqUsage = `用法:
+  dlq list               列出死信列表中的消息
+  dlq replay <id>|--all  将死信放回推送队列重新推送
+  dlq purge <id>|--all   从死信列表中删除消息`
+
+// runCommand 执行命令行子命令
+func runCommand(ctx context.Context, client db.DatabaseClient, args []string) error {
+	switch args[0] {
+	case "dlq":
+		return runDLQ(ctx, client, args[1:])
+	default:
+		return fmt.Errorf("未知命令 %s\n%s", args[0], dlqUsage)
+	}
+}
+
+// runDLQ 查看、重放或删除死信
+func runDLQ(ctx context.Context, client db.DatabaseClient, args []string) error {
+	if len(args) == 0 {
+		return errors.New(dlqUsage)
+	}
+
+	deliveries, err := outbox.DeadLetters(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\t目的地\t次数\t失败时间\t标题\t错误")
+		for _, delivery := range deliveries {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", delivery.ID, delivery.Destination, delivery.Attempts,
+				delivery.FailedAt.Format(time.DateTime), delivery.Item.Title, delivery.LastError)
+		}
+		w.Flush()
+		fmt.Printf("共 %d 条死信\n", len(deliveries))
+		return nil
+	case "replay", "purge":
+		if len(args) < 2 {
+			return errors.New(dlqUsage)
+		}
+		action, count := "重放", 0
+		if args[0] == "purge" {
+			action = "删除"
+		}
+		for _, delivery := range deliveries {
+			if args[1] != "--all" && delivery.ID != args[1] {
+				continue
+			}
+			if args[0] == "replay" && delivery.Raw != "" && args[1] == "--all" {
+				fmt.Printf("跳过无法解析的死信 %s\n", delivery.ID)
+				continue
+			}
+			if args[0] == "replay" {
+				err = outbox.Replay(ctx, client, delivery)
+			} else {
+				err = outbox.Purge(ctx, client, delivery.ID)
+			}
+			if err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 && args[1] != "--all" {
+			return fmt.Errorf("死信 %s 不存在", args[1])
+		}
+		fmt.Printf("已%s %d 条死信\n", action, count)
+		return nil
+	default:
+		return fmt.Errorf("未知的 dlq 子命令 %s\n%s", args[0], dlqUsage)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"code/db/dbtest"
+	"code/notify"
+	"code/outbox"
+)
+
+// newDeadLetters 创建包含两条可解析死信和一条无法解析死信的内存数据库
+func newDeadLetters(t *testing.T) *dbtest.Memory {
+	t.Helper()
+	ctx := context.Background()
+	client := dbtest.NewMemory()
+	for _, id := range []string{"d|a", "d|b"} {
+		payload, _ := json.Marshal(outbox.Delivery{ID: id, Destination: "d", Item: notify.Item{Title: id}, Attempts: 8, LastError: "timeout"})
+		client.MoveToDeadLetter(ctx, outbox.Queue, id, string(payload))
+	}
+	client.MoveToDeadLetter(ctx, outbox.Queue, "d|broken", "{not json")
+	return client
+}
+
+func deadLetterIDs(t *testing.T, client *dbtest.Memory) []string {
+	t.Helper()
+	deliveries, err := outbox.DeadLetters(context.Background(), client)
+	if err != nil {
+		t.Fatalf("DeadLetters: %v", err)
+	}
+	var ids []string
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	return ids
+}
+
+func TestRunDLQ(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    bool
+		wantDead   []string
+		wantQueued int
+	}{
+		{"列出", []string{"list"}, false, []string{"d|a", "d|b", "d|broken"}, 0},
+		{"重放一条", []string{"replay", "d|a"}, false, []string{"d|b", "d|broken"}, 1},
+		{"重放全部时跳过无法解析的死信", []string{"replay", "--all"}, false, []string{"d|broken"}, 2},
+		{"重放无法解析的死信", []string{"replay", "d|broken"}, true, []string{"d|a", "d|b", "d|broken"}, 0},
+		{"重放不存在的死信", []string{"replay", "d|missing"}, true, []string{"d|a", "d|b", "d|broken"}, 0},
+		{"删除无法解析的死信", []string{"purge", "d|broken"}, false, []string{"d|a", "d|b"}, 0},
+		{"删除全部", []string{"purge", "--all"}, false, nil, 0},
+		{"缺少 ID", []string{"purge"}, true, []string{"d|a", "d|b", "d|broken"}, 0},
+		{"未知子命令", []string{"drop"}, true, []string{"d|a", "d|b", "d|broken"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newDeadLetters(t)
+			err := runCommand(context.Background(), client, append([]string{"dlq"}, tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runCommand 错误 = %v, 期望出错 %v", err, tt.wantErr)
+			}
+			if got := deadLetterIDs(t, client); !slices.Equal(got, tt.wantDead) {
+				t.Errorf("死信 = %v, 期望 %v", got, tt.wantDead)
+			}
+			if queued := len(client.Queue(outbox.Queue)); queued != tt.wantQueued {
+				t.Errorf("推送队列中有 %d 条, 期望 %d 条", queued, tt.wantQueued)
+			}
+		})
+	}
+}
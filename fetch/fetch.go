@@ -1,8 +1,12 @@
 package fetch
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -12,8 +16,64 @@ import (
 	"golang.org/x/text/transform"
 )
 
-// Fetch 获取网页内容
-func Fetch(url string, timeout time.Duration) (string, error) {
+// Request 描述一次抓取请求，用于需要指定请求方法、请求头或请求体的接口
+type Request struct {
+	URL     string
+	Method  string            // 默认为 GET
+	Headers map[string]string // 额外的请求头
+	Body    string            // 请求体
+
+	// 上次响应的缓存校验信息，非空时 GET 请求会带上 If-None-Match / If-Modified-Since 发起条件请求
+	ETag         string
+	LastModified string
+}
+
+// Response 是一次抓取的结果
+type Response struct {
+	Body         string // 原始响应内容，未做编码转换
+	NotModified  bool   // 服务器返回 304，内容与上次相同，Body 为空
+	ETag         string // 响应的 ETag；304 响应未返回时沿用请求中的值
+	LastModified string // 响应的 Last-Modified；304 响应未返回时沿用请求中的值
+	Hash         string // 响应内容的哈希，用于在服务器不支持条件请求时判断内容是否变化
+}
+
+// Fetch 获取网页内容，ctx 取消时中止请求
+func Fetch(ctx context.Context, url string, timeout time.Duration) (string, error) {
+	content, err := FetchRequest(ctx, Request{URL: url}, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	// 处理并返回抓取的HTML内容
+	return DecodeHTML(content)
+}
+
+// DecodeHTML 按 meta 标签中声明的编码将 HTML 内容转换为 UTF-8
+func DecodeHTML(content string) (string, error) {
+	utf8Content, err := determineEncoding(content) // 这里调用 `determineEncoding` 来处理抓取到的 HTML 内容
+	if err != nil {
+		return "", fmt.Errorf("编码转换错误: %v", err)
+	}
+
+	return utf8Content, nil
+}
+
+// FetchRequest 按请求描述获取原始响应内容，不做编码转换；ctx 取消时中止请求
+func FetchRequest(ctx context.Context, req Request, timeout time.Duration) (string, error) {
+	resp, err := FetchResponse(ctx, req, timeout)
+	if err != nil {
+		return "", err
+	}
+	return resp.Body, nil
+}
+
+// FetchResponse 按请求描述获取原始响应内容及缓存校验信息，不做编码转换；
+// 请求带有 ETag 或 LastModified 时发起条件请求，服务器返回 304 时 NotModified 为 true
+func FetchResponse(ctx context.Context, req Request, timeout time.Duration) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 创建一个新的 Colly 爬虫
 	c := colly.NewCollector(
 		// 设置请求超时
@@ -23,6 +83,9 @@ func Fetch(url string, timeout time.Duration) (string, error) {
 	// 设置请求超时
 	c.SetRequestTimeout(timeout)
 
+	// colly 不支持 context，通过 Transport 为每个请求附加 ctx
+	c.WithTransport(&contextTransport{ctx: ctx, next: http.DefaultTransport})
+
 	// 设置 User-Agent 模拟浏览器
 	c.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36"
 
@@ -30,20 +93,35 @@ func Fetch(url string, timeout time.Duration) (string, error) {
 	// c.OnRequest(func(r *colly.Request) {
 	// 	r.Headers.Set("Cookie", "__jsluid_h=382abac99be2999e55b92c39875af9e2; __jsl_clearance=1731595344.967|0|djiPf%2FlegL2y5Oy60KMb669en5M%3D")
 	// 	// 设置 Referer
-	// 	r.Headers.Set("Referer", url)
+	// 	r.Headers.Set("Referer", req.URL)
 	// })
 
-	var content string
+	resp := &Response{ETag: req.ETag, LastModified: req.LastModified}
+	var requestErr error
 
 	// 设置请求回调函数来获取页面的 HTML 内容
 	c.OnResponse(func(r *colly.Response) {
 		// 获取整个响应的 HTML 内容
-		content = string(r.Body)
+		resp.Body = string(r.Body)
+		resp.ETag = r.Headers.Get("ETag")
+		resp.LastModified = r.Headers.Get("Last-Modified")
 	})
 
 	// 错误处理回调
 	c.OnError(func(r *colly.Response, err error) {
+		// colly 把 304 当作错误处理，这里识别为内容未变化
+		if r != nil && r.StatusCode == http.StatusNotModified {
+			resp.NotModified = true
+			if etag := r.Headers.Get("ETag"); etag != "" {
+				resp.ETag = etag
+			}
+			if lastModified := r.Headers.Get("Last-Modified"); lastModified != "" {
+				resp.LastModified = lastModified
+			}
+			return
+		}
 		fmt.Printf("请求错误: %v\n", err)
+		requestErr = err
 	})
 
 	// 设置随机请求延迟，防止频繁请求被检测
@@ -52,22 +130,64 @@ func Fetch(url string, timeout time.Duration) (string, error) {
 	// 	RandomDelay: 2 * time.Second, // 每次请求之间随机延迟 2 秒
 	// })
 
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	headers := http.Header{"User-Agent": []string{c.UserAgent}}
+	for key, value := range req.Headers {
+		headers.Set(key, value)
+	}
+	if method == http.MethodGet {
+		if req.ETag != "" {
+			headers.Set("If-None-Match", req.ETag)
+		}
+		if req.LastModified != "" {
+			headers.Set("If-Modified-Since", req.LastModified)
+		}
+	}
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+
 	// 开始抓取页面
-	err := c.Visit(url)
+	err := c.Request(method, req.URL, body, nil, headers)
 	if err != nil {
-		return "", fmt.Errorf("colly 错误: %v", err)
+		return nil, fmt.Errorf("colly 错误: %v", err)
 	}
 
 	// 等待爬虫完成抓取
 	c.Wait()
 
-	// 处理并返回抓取的HTML内容
-	utf8Content, err := determineEncoding(content) // 这里调用 `determineEncoding` 来处理抓取到的 HTML 内容
-	if err != nil {
-		return "", fmt.Errorf("编码转换错误: %v", err)
+	if requestErr != nil {
+		return nil, fmt.Errorf("请求 %s 失败: %v", req.URL, requestErr)
 	}
 
-	return utf8Content, nil
+	if !resp.NotModified {
+		resp.Hash = contentHash(resp.Body)
+	}
+	return resp, nil
+}
+
+// contentHash 返回响应内容的 SHA-256 哈希
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// contextTransport 为请求附加 ctx 的 http.RoundTripper
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// RoundTrip 在 ctx 取消时中止请求，同时保留请求原有的超时；请求结束后注销对 ctx 的监听
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCtx, cancel := context.WithCancel(req.Context())
+	stop := context.AfterFunc(t.ctx, cancel)
+	context.AfterFunc(reqCtx, func() { stop() })
+	return t.next.RoundTrip(req.WithContext(reqCtx))
 }
 
 // determineEncoding 检测并转换 HTML 内容编码
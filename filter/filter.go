@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code/config"
+)
+
+// Filter 是编译后的标题过滤规则
+type Filter struct {
+	include      []string
+	exclude      []string
+	includeRegex []*regexp.Regexp
+	excludeRegex []*regexp.Regexp
+}
+
+// New 编译标题过滤规则，关键词不区分大小写
+func New(cfg config.FilterConfig) (*Filter, error) {
+	f := &Filter{
+		include: lowerAll(cfg.Include),
+		exclude: lowerAll(cfg.Exclude),
+	}
+	var err error
+	if f.includeRegex, err = compileAll(cfg.IncludeRegex); err != nil {
+		return nil, err
+	}
+	if f.excludeRegex, err = compileAll(cfg.ExcludeRegex); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Match 检查标题（原文和译文）是否通过过滤：命中任一排除条件时不通过；配置了包含条件时至少命中一个。
+// 通过时返回标题中命中包含条件的文本，用于在消息中高亮
+func (f *Filter) Match(titles ...string) (bool, []string) {
+	for _, title := range titles {
+		if len(findKeywords(title, f.exclude)) > 0 || len(findRegex(title, f.excludeRegex)) > 0 {
+			return false, nil
+		}
+	}
+	if len(f.include) == 0 && len(f.includeRegex) == 0 {
+		return true, nil
+	}
+
+	var matches []string
+	for _, title := range titles {
+		matches = append(matches, findKeywords(title, f.include)...)
+		matches = append(matches, findRegex(title, f.includeRegex)...)
+	}
+	return len(matches) > 0, Dedup(matches)
+}
+
+// Dedup 去掉重复和空的关键词，按长度从长到短排序，高亮时优先替换较长的关键词
+func Dedup(keywords []string) []string {
+	seen := make(map[string]bool)
+	result := keywords[:0:0]
+	for _, keyword := range keywords {
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		result = append(result, keyword)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+// findKeywords 返回标题中出现的关键词，保留标题中的原始大小写
+func findKeywords(title string, keywords []string) []string {
+	var matches []string
+	lower := strings.ToLower(title)
+	for _, keyword := range keywords {
+		if index := strings.Index(lower, keyword); index >= 0 && len(lower) == len(title) {
+			matches = append(matches, title[index:index+len(keyword)])
+		} else if index >= 0 {
+			// 大小写转换改变了字节长度，无法定位原文，直接使用关键词
+			matches = append(matches, keyword)
+		}
+	}
+	return matches
+}
+
+// findRegex 返回标题中匹配正则表达式的文本
+func findRegex(title string, patterns []*regexp.Regexp) []string {
+	var matches []string
+	for _, pattern := range patterns {
+		matches = append(matches, pattern.FindAllString(title, -1)...)
+	}
+	return matches
+}
+
+// compileAll 编译正则表达式
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式 %q 无效: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// lowerAll 将关键词转换为小写，忽略空关键词
+func lowerAll(keywords []string) []string {
+	lowered := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword != "" {
+			lowered = append(lowered, strings.ToLower(keyword))
+		}
+	}
+	return lowered
+}
+
+// Highlight 用 mark 包裹文本中出现的关键词，其余部分用 plain 处理（例如转义）；关键词重叠时优先匹配较长的
+func Highlight(text string, keywords []string, mark func(string) string, plain func(string) string) string {
+	keywords = Dedup(keywords)
+	if len(keywords) == 0 {
+		return plain(text)
+	}
+
+	quoted := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		quoted[i] = regexp.QuoteMeta(keyword)
+	}
+	pattern := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(plain(text[last:loc[0]]))
+		b.WriteString(mark(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(plain(text[last:]))
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"code/config"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.FilterConfig
+		titles []string
+		want   bool
+		words  []string
+	}{
+		{"没有过滤条件时全部通过", config.FilterConfig{}, []string{"Anything"}, true, nil},
+		{"命中包含关键词", config.FilterConfig{Include: []string{"GPU"}}, []string{"New GPU launched"}, true, []string{"GPU"}},
+		{"关键词不区分大小写并保留原文大小写", config.FilterConfig{Include: []string{"gpu"}}, []string{"New GPU launched"}, true, []string{"GPU"}},
+		{"未命中包含关键词", config.FilterConfig{Include: []string{"GPU"}}, []string{"Quarterly dividend"}, false, nil},
+		{"排除优先于包含", config.FilterConfig{Include: []string{"GPU"}, Exclude: []string{"dividend"}}, []string{"GPU maker declares dividend"}, false, nil},
+		{"排除关键词不区分大小写", config.FilterConfig{Exclude: []string{"DIVIDEND"}}, []string{"Quarterly dividend"}, false, nil},
+		{"译文命中排除关键词", config.FilterConfig{Exclude: []string{"分红"}}, []string{"Quarterly dividend", "季度分红"}, false, nil},
+		{"译文命中包含关键词", config.FilterConfig{Include: []string{"降息"}}, []string{"Rate cut", "央行宣布降息"}, true, []string{"降息"}},
+		{"包含正则", config.FilterConfig{IncludeRegex: []string{`Phase [0-9]`}}, []string{"Drug enters Phase 3 trial"}, true, []string{"Phase 3"}},
+		{"正则区分大小写", config.FilterConfig{IncludeRegex: []string{`Phase [0-9]`}}, []string{"phase 3 trial"}, false, nil},
+		{"正则可用 (?i) 忽略大小写", config.FilterConfig{IncludeRegex: []string{`(?i)phase [0-9]`}}, []string{"PHASE 3 trial"}, true, []string{"PHASE 3"}},
+		{"排除正则优先于包含关键词", config.FilterConfig{Include: []string{"trial"}, ExcludeRegex: []string{`^Webinar`}}, []string{"Webinar: trial design"}, false, nil},
+		{"关键词和正则同时命中", config.FilterConfig{Include: []string{"FDA"}, IncludeRegex: []string{`approv\w+`}}, []string{"FDA approves drug"}, true, []string{"approves", "FDA"}},
+		{"重复命中只保留一次", config.FilterConfig{Include: []string{"fed", "Fed"}}, []string{"Fed holds rates"}, true, []string{"Fed"}},
+		{"忽略空关键词", config.FilterConfig{Include: []string{""}}, []string{"Anything"}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			got, words := f.Match(tt.titles...)
+			if got != tt.want {
+				t.Errorf("Match = %v, 期望 %v", got, tt.want)
+			}
+			if !slices.Equal(words, tt.words) {
+				t.Errorf("命中的关键词 = %q, 期望 %q", words, tt.words)
+			}
+		})
+	}
+}
+
+func TestNewInvalidRegex(t *testing.T) {
+	if _, err := New(config.FilterConfig{ExcludeRegex: []string{"("}}); err == nil {
+		t.Error("无效的正则表达式应返回错误")
+	}
+}
+
+func TestDedup(t *testing.T) {
+	got := Dedup([]string{"Fed", "", "rate cut", "Fed", "rate"})
+	want := []string{"rate cut", "rate", "Fed"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Dedup = %q, 期望 %q", got, want)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	mark := func(s string) string { return "[" + s + "]" }
+	plain := func(s string) string { return strings.ReplaceAll(s, "*", `\*`) }
+
+	tests := []struct {
+		name     string
+		text     string
+		keywords []string
+		want     string
+	}{
+		{"没有关键词时只做转义", "a*b", nil, `a\*b`},
+		{"标出关键词并转义其余部分", "GPU *sale*", []string{"GPU"}, `[GPU] \*sale\*`},
+		{"不区分大小写并保留原文", "new gpu and GPU", []string{"Gpu"}, "new [gpu] and [GPU]"},
+		{"重叠时优先较长的关键词", "rate cut and rate", []string{"rate", "rate cut"}, "[rate cut] and [rate]"},
+		{"关键词中的特殊字符按字面匹配", "S&P 500 (SPX)", []string{"(SPX)"}, "S&P 500 [(SPX)]"},
+		{"中文关键词", "央行宣布降息", []string{"降息"}, "央行宣布[降息]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.keywords, mark, plain); got != tt.want {
+				t.Errorf("Highlight = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
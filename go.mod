@@ -5,9 +5,13 @@ go 1.23
 toolchain go1.23.2
 
 require (
+	github.com/PuerkitoBio/goquery v1.5.1
 	github.com/anaskhan96/soup v1.2.5
+	github.com/andybalholm/cascadia v1.2.0
+	github.com/antchfx/htmlquery v1.2.3
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/gocolly/colly/v2 v2.1.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common v1.0.1040
 	github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tmt v1.0.1040
 	golang.org/x/net v0.27.0
@@ -16,9 +20,6 @@ require (
 )
 
 require (
-	github.com/PuerkitoBio/goquery v1.5.1 // indirect
-	github.com/andybalholm/cascadia v1.2.0 // indirect
-	github.com/antchfx/htmlquery v1.2.3 // indirect
 	github.com/antchfx/xmlquery v1.2.4 // indirect
 	github.com/antchfx/xpath v1.1.8 // indirect
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
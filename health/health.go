@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"code/config"
+	"code/db"
+)
+
+// State 是站点的抓取健康状态，以 JSON 保存在数据库中
+type State struct {
+	ConsecutiveFailures int       `json:"consecutive_failures"` // 连续失败次数
+	LastError           string    `json:"last_error,omitempty"` // 最近一次失败的原因
+	LastFailure         time.Time `json:"last_failure"`
+	LastSuccess         time.Time `json:"last_success"`
+	NextAttempt         time.Time `json:"next_attempt"` // 退避期间下次允许抓取的时间
+	Open                bool      `json:"open"`         // 熔断器是否打开（站点已判定为故障）
+}
+
+// Tracker 记录站点的连续失败次数，按指数退避推迟失败站点的下次抓取，连续失败达到阈值时打开熔断器
+type Tracker struct {
+	client db.DatabaseClient
+	cfg    config.HealthConfig
+}
+
+// NewTracker 创建站点健康状态追踪器
+func NewTracker(client db.DatabaseClient, cfg config.HealthConfig) *Tracker {
+	return &Tracker{client: client, cfg: cfg}
+}
+
+// healthKey 返回站点健康状态在数据库中的键名
+func healthKey(site string) string {
+	return "health:" + site
+}
+
+// Load 读取站点的健康状态，没有记录时返回零值
+func (t *Tracker) Load(ctx context.Context, site string) State {
+	var state State
+	value, err := t.client.GetKey(ctx, healthKey(site))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return State{}
+	}
+	return state
+}
+
+// save 保存站点的健康状态
+func (t *Tracker) save(ctx context.Context, site string, state State) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("健康状态序列化失败: %v", err)
+	}
+	return t.client.SetKey(ctx, healthKey(site), string(value))
+}
+
+// Allow 返回站点现在是否可以抓取：退避期间或熔断期间未到下次抓取时间时返回 false；熔断期间到时间后放行一次探测
+func (t *Tracker) Allow(ctx context.Context, site string, now time.Time) (bool, State) {
+	state := t.Load(ctx, site)
+	return !now.Before(state.NextAttempt), state
+}
+
+// Failure 记录一次抓取失败并计算下次抓取时间，interval 为站点正常的抓取间隔；
+// 连续失败次数首次达到阈值时 opened 为 true，调用方据此发送一次故障告警
+func (t *Tracker) Failure(ctx context.Context, site string, interval time.Duration, cause error, now time.Time) (state State, opened bool, err error) {
+	state = t.Load(ctx, site)
+	state.ConsecutiveFailures++
+	state.LastError = cause.Error()
+	state.LastFailure = now
+	state.NextAttempt = now.Add(t.backoff(interval, state.ConsecutiveFailures))
+	if !state.Open && state.ConsecutiveFailures >= t.cfg.FailureThreshold {
+		state.Open = true
+		opened = true
+	}
+	return state, opened, t.save(ctx, site, state)
+}
+
+// Success 记录一次抓取成功并清除失败记录；熔断器之前处于打开状态时 recovered 为 true，调用方据此发送恢复通知
+func (t *Tracker) Success(ctx context.Context, site string, now time.Time) (previous State, recovered bool, err error) {
+	previous = t.Load(ctx, site)
+	if previous.ConsecutiveFailures == 0 && !previous.LastSuccess.IsZero() {
+		return previous, false, nil
+	}
+	state := State{LastSuccess: now, LastFailure: previous.LastFailure, LastError: previous.LastError}
+	return previous, previous.Open, t.save(ctx, site, state)
+}
+
+// backoff 返回第 failures 次连续失败后的等待时间：第一次失败按正常间隔重试，之后每次翻倍，不超过 MaxBackoff；
+// 熔断器打开后固定按 MaxBackoff 探测
+func (t *Tracker) backoff(interval time.Duration, failures int) time.Duration {
+	if failures >= t.cfg.FailureThreshold {
+		return t.cfg.MaxBackoff
+	}
+	delay := interval
+	for i := 1; i < failures && delay < t.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > t.cfg.MaxBackoff {
+		delay = t.cfg.MaxBackoff
+	}
+	return delay
+}
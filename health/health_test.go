@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code/config"
+	"code/db/dbtest"
+)
+
+func TestBackoff(t *testing.T) {
+	tracker := NewTracker(nil, config.HealthConfig{FailureThreshold: 5, MaxBackoff: time.Hour})
+	tests := []struct {
+		interval time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{2 * time.Minute, 1, 2 * time.Minute},
+		{2 * time.Minute, 2, 4 * time.Minute},
+		{2 * time.Minute, 3, 8 * time.Minute},
+		{2 * time.Minute, 4, 16 * time.Minute},
+		// 达到阈值后固定按 MaxBackoff 探测
+		{2 * time.Minute, 5, time.Hour},
+		{2 * time.Minute, 12, time.Hour},
+		// 未达到阈值时也不超过 MaxBackoff
+		{20 * time.Minute, 3, time.Hour},
+		{2 * time.Hour, 1, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := tracker.backoff(tt.interval, tt.failures); got != tt.want {
+			t.Errorf("backoff(%s, %d) = %s, 期望 %s", tt.interval, tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestTrackerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewTracker(dbtest.NewMemory(), config.HealthConfig{FailureThreshold: 3, MaxBackoff: time.Hour})
+	now := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+	cause := errors.New("HTTP 503")
+
+	tests := []struct {
+		name        string
+		success     bool
+		wantOpened  bool
+		wantRecover bool
+		wantOpen    bool
+		wantNext    time.Duration // 相对本次时间的下次抓取时间
+	}{
+		{"第 1 次失败", false, false, false, false, time.Minute},
+		{"第 2 次失败", false, false, false, false, 2 * time.Minute},
+		{"达到阈值时打开熔断器", false, true, false, true, time.Hour},
+		{"熔断期间再次失败不重复告警", false, false, false, true, time.Hour},
+		{"探测成功后恢复", true, false, true, false, 0},
+		{"恢复后再次成功不重复通知", true, false, false, false, 0},
+		{"恢复后重新计数", false, false, false, false, time.Minute},
+	}
+	for _, tt := range tests {
+		now = now.Add(time.Minute)
+		if tt.success {
+			_, recovered, err := tracker.Success(ctx, "site", now)
+			if err != nil {
+				t.Fatalf("%s: Success: %v", tt.name, err)
+			}
+			if recovered != tt.wantRecover {
+				t.Errorf("%s: recovered = %v, 期望 %v", tt.name, recovered, tt.wantRecover)
+			}
+		} else {
+			_, opened, err := tracker.Failure(ctx, "site", time.Minute, cause, now)
+			if err != nil {
+				t.Fatalf("%s: Failure: %v", tt.name, err)
+			}
+			if opened != tt.wantOpened {
+				t.Errorf("%s: opened = %v, 期望 %v", tt.name, opened, tt.wantOpened)
+			}
+		}
+
+		state := tracker.Load(ctx, "site")
+		if state.Open != tt.wantOpen {
+			t.Errorf("%s: Open = %v, 期望 %v", tt.name, state.Open, tt.wantOpen)
+		}
+		if tt.wantNext > 0 && !state.NextAttempt.Equal(now.Add(tt.wantNext)) {
+			t.Errorf("%s: NextAttempt = %s, 期望 %s", tt.name, state.NextAttempt, now.Add(tt.wantNext))
+		}
+		allowed, _ := tracker.Allow(ctx, "site", now)
+		if allowed != (tt.wantNext == 0) {
+			t.Errorf("%s: Allow = %v, 期望 %v", tt.name, allowed, tt.wantNext == 0)
+		}
+	}
+
+	state := tracker.Load(ctx, "site")
+	if state.LastError != cause.Error() || state.ConsecutiveFailures != 1 {
+		t.Errorf("State = %+v, 期望保留最近一次错误且连续失败 1 次", state)
+	}
+}
+
+func TestAllowAfterBackoff(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewTracker(dbtest.NewMemory(), config.HealthConfig{FailureThreshold: 3, MaxBackoff: time.Hour})
+	now := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+
+	if allowed, _ := tracker.Allow(ctx, "site", now); !allowed {
+		t.Error("没有记录的站点应允许抓取")
+	}
+	tracker.Failure(ctx, "site", 5*time.Minute, errors.New("timeout"), now)
+	if allowed, _ := tracker.Allow(ctx, "site", now.Add(5*time.Minute-time.Second)); allowed {
+		t.Error("退避期间不应允许抓取")
+	}
+	if allowed, _ := tracker.Allow(ctx, "site", now.Add(5*time.Minute)); !allowed {
+		t.Error("到下次抓取时间后应允许抓取")
+	}
+}
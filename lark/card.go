@@ -0,0 +1,208 @@
+package lark
+
+import (
+	"strings"
+
+	"code/filter"
+)
+
+// CardMessage 是飞书的消息卡片（interactive）消息
+type CardMessage struct {
+	Signature
+	MsgType string `json:"msg_type"`
+	Card    *Card  `json:"card"`
+}
+
+// Card 是消息卡片的内容
+type Card struct {
+	Config   *CardConfig   `json:"config,omitempty"`
+	Header   *CardHeader   `json:"header,omitempty"`
+	Elements []CardElement `json:"elements"`
+}
+
+// CardConfig 是消息卡片的全局配置
+type CardConfig struct {
+	WideScreenMode bool `json:"wide_screen_mode"`
+	EnableForward  bool `json:"enable_forward"`
+}
+
+// CardHeader 是消息卡片的标题栏，Template 决定标题栏颜色
+type CardHeader struct {
+	Title    CardText `json:"title"`
+	Template string   `json:"template,omitempty"`
+}
+
+// CardText 是卡片中的文本，Tag 为 plain_text 或 lark_md
+type CardText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+
+// CardElement 是卡片中的模块，Tag 为 div、action、hr 或 note
+type CardElement struct {
+	Tag      string       `json:"tag"`
+	Text     *CardText    `json:"text,omitempty"`     // div 的文本
+	Fields   []CardField  `json:"fields,omitempty"`   // div 的多列字段
+	Actions  []CardAction `json:"actions,omitempty"`  // action 的按钮
+	Elements []CardText   `json:"elements,omitempty"` // note 的备注内容
+}
+
+// CardField 是 div 模块中的一个字段
+type CardField struct {
+	IsShort bool     `json:"is_short"`
+	Text    CardText `json:"text"`
+}
+
+// CardAction 是带链接的按钮
+type CardAction struct {
+	Tag  string   `json:"tag"`
+	Text CardText `json:"text"`
+	URL  string   `json:"url"`
+	Type string   `json:"type"` // default、primary 或 danger
+}
+
+// 文本类型
+const (
+	TextPlain    = "plain_text"
+	TextMarkdown = "lark_md"
+)
+
+// DefaultTemplate 是未配置分类颜色时的标题栏颜色
+const DefaultTemplate = "blue"
+
+// CategoryTemplates 是分类对应的标题栏颜色，可通过 NewsCard.Template 单独指定
+var CategoryTemplates = map[string]string{
+	"tech":      "blue",
+	"pharma":    "green",
+	"macro":     "red",
+	"policy":    "orange",
+	"finance":   "purple",
+	"alert":     "red",   // 站点故障告警
+	"recovered": "green", // 站点恢复通知
+}
+
+// NewsCard 是生成新闻卡片所需的内容
+type NewsCard struct {
+	Site            string // 站点名称，显示在标题栏和来源标签中
+	Category        string // 站点分类，决定标题栏颜色
+	Template        string // 标题栏颜色，为空时按分类选择
+	Title           string // 显示的标题
+	OriginalTitle   string // 原始标题
+	TranslatedTitle string // 标题译文，未翻译时为空
+	Link            string
+	Date            string   // 已格式化的日期
+	Keywords        []string // 标题中需要高亮的关键词
+	Summary         string   // 附加说明，显示在标题下方
+}
+
+// BuildNewsCard 生成新闻消息卡片：标题栏显示站点名称并按分类着色，标题作为链接按钮，附带日期、原文和来源标签
+func BuildNewsCard(news NewsCard) *Card {
+	template := news.Template
+	if template == "" {
+		template = CategoryTemplates[strings.ToLower(news.Category)]
+	}
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	elements := []CardElement{
+		{
+			Tag: "action",
+			Actions: []CardAction{{
+				Tag:  "button",
+				Text: CardText{Tag: TextPlain, Content: news.Title},
+				URL:  news.Link,
+				Type: "primary",
+			}},
+		},
+	}
+
+	// 标题经过翻译且与原文不同时同时显示译文和原文
+	if news.TranslatedTitle != "" && news.TranslatedTitle != news.OriginalTitle {
+		elements = append(elements, CardElement{
+			Tag: "div",
+			Fields: []CardField{
+				{Text: CardText{Tag: TextMarkdown, Content: "**译文**\n" + highlightMarkdown(news.TranslatedTitle, news.Keywords)}},
+				{Text: CardText{Tag: TextMarkdown, Content: "**原文**\n" + highlightMarkdown(news.OriginalTitle, news.Keywords)}},
+			},
+		})
+	}
+
+	// 标题命中过滤关键词时列出关键词，并在卡片正文中高亮标题
+	if len(news.Keywords) > 0 {
+		if news.TranslatedTitle == "" || news.TranslatedTitle == news.OriginalTitle {
+			elements = append(elements, CardElement{
+				Tag:  "div",
+				Text: &CardText{Tag: TextMarkdown, Content: highlightMarkdown(news.Title, news.Keywords)},
+			})
+		}
+		keywords := make([]string, len(news.Keywords))
+		for i, keyword := range news.Keywords {
+			keywords[i] = highlightMarkdown(keyword, news.Keywords)
+		}
+		elements = append(elements, CardElement{
+			Tag:  "div",
+			Text: &CardText{Tag: TextMarkdown, Content: "🏷️ **关键词** " + strings.Join(keywords, " ")},
+		})
+	}
+
+	if news.Summary != "" {
+		elements = append(elements, CardElement{
+			Tag:  "div",
+			Text: &CardText{Tag: TextPlain, Content: news.Summary},
+		})
+	}
+
+	elements = append(elements,
+		CardElement{
+			Tag: "div",
+			Fields: []CardField{
+				{IsShort: true, Text: CardText{Tag: TextMarkdown, Content: "📅 **日期**\n" + news.Date}},
+				{IsShort: true, Text: CardText{Tag: TextMarkdown, Content: "🔗 **链接**\n[" + escapeMarkdown(news.Site) + "](" + news.Link + ")"}},
+			},
+		},
+		CardElement{Tag: "hr"},
+		CardElement{
+			Tag:      "note",
+			Elements: []CardText{{Tag: TextPlain, Content: sourceTag(news)}},
+		},
+	)
+
+	return &Card{
+		Config: &CardConfig{WideScreenMode: true, EnableForward: true},
+		Header: &CardHeader{
+			Title:    CardText{Tag: TextPlain, Content: "📢 " + news.Site},
+			Template: template,
+		},
+		Elements: elements,
+	}
+}
+
+// initCardMessage 初始化一个消息卡片消息
+func initCardMessage(card *Card) *CardMessage {
+	return &CardMessage{
+		MsgType: "interactive",
+		Card:    card,
+	}
+}
+
+// sourceTag 返回卡片底部的来源标签
+func sourceTag(news NewsCard) string {
+	if news.Category == "" {
+		return "来源：" + news.Site
+	}
+	return "来源：" + news.Site + " · #" + news.Category
+}
+
+// highlightMarkdown 转义 lark_md 文本，并将其中的关键词标为红色粗体
+func highlightMarkdown(text string, keywords []string) string {
+	return filter.Highlight(text, keywords, func(keyword string) string {
+		return "<font color='red'>**" + escapeMarkdown(keyword) + "**</font>"
+	}, escapeMarkdown)
+}
+
+// escapeMarkdown 转义 lark_md 中有特殊含义的字符
+func escapeMarkdown(text string) string {
+	replacer := strings.NewReplacer("*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]", "~", "\\~", "`", "\\`")
+	return replacer.Replace(text)
+}
@@ -0,0 +1,87 @@
+package lark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// 飞书自定义机器人常见的错误码
+const (
+	CodeRateLimited      = 9499  // 请求过于频繁
+	CodeFrequencyLimited = 11232 // 消息发送频率超限
+	CodeSignMismatch     = 19021 // 签名校验失败，通常是 secret 错误或服务器时间偏差过大
+	CodeIPNotAllowed     = 19022 // 请求 IP 不在白名单中
+	CodeKeywordNotFound  = 19024 // 消息不包含机器人设置的关键词
+)
+
+// APIError 是飞书 webhook 返回的错误，HTTP 状态码正常但响应中 code 不为 0 时同样会返回
+type APIError struct {
+	StatusCode int    // HTTP 状态码
+	Code       int    // 飞书错误码，HTTP 请求失败且响应中没有错误码时为 0
+	Msg        string // 飞书错误信息或响应内容
+}
+
+// Error 返回错误描述
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("飞书推送失败: code=%d msg=%s", e.Code, e.Msg)
+	}
+	return fmt.Sprintf("飞书推送失败: HTTP %d %s", e.StatusCode, e.Msg)
+}
+
+// Retryable 返回稍后重试是否可能成功：限流、HTTP 429 和 5xx 可以重试，签名、IP 白名单、关键词等错误重试也不会成功
+func (e *APIError) Retryable() bool {
+	switch e.Code {
+	case CodeRateLimited, CodeFrequencyLimited:
+		return true
+	case 0:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	default:
+		return false
+	}
+}
+
+// IsRetryable 返回推送错误是否可以重试，网络错误等非 APIError 视为可以重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
+
+// response 是飞书 webhook 的响应，新版接口返回 code/msg，旧版返回 StatusCode/StatusMessage
+type response struct {
+	Code          *int   `json:"code"`
+	Msg           string `json:"msg"`
+	StatusCode    *int   `json:"StatusCode"`
+	StatusMessage string `json:"StatusMessage"`
+}
+
+// checkResponse 检查飞书 webhook 的响应，推送失败时返回 *APIError
+func checkResponse(statusCode int, body []byte) error {
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		if statusCode != http.StatusOK {
+			return &APIError{StatusCode: statusCode, Msg: string(body)}
+		}
+		return fmt.Errorf("飞书响应解析失败: %v", err)
+	}
+
+	code, msg := 0, resp.Msg
+	switch {
+	case resp.Code != nil:
+		code = *resp.Code
+	case resp.StatusCode != nil:
+		code, msg = *resp.StatusCode, resp.StatusMessage
+	}
+	if code != 0 || statusCode != http.StatusOK {
+		return &APIError{StatusCode: statusCode, Code: code, Msg: msg}
+	}
+	return nil
+}
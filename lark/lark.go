@@ -2,15 +2,47 @@ package lark
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 )
 
-// PushToLark 将消息推送到飞书
-func PushToLark(webhookURL, message string) error {
+// PushToLark 将文本消息推送到飞书，secret 不为空时按机器人的签名校验规则附加 timestamp 和 sign；ctx 取消时中止请求
+// client 为发送请求的 HTTP 客户端，为 nil 时使用 http.DefaultClient
+func PushToLark(ctx context.Context, client *http.Client, webhookURL, secret, message string) error {
 	// 创建消息体
 	payload := initSimpleMessage(message)
+	return push(ctx, client, webhookURL, secret, &payload.Signature, payload)
+}
+
+// PushCardToLark 将消息卡片推送到飞书，参数的含义与 PushToLark 相同
+func PushCardToLark(ctx context.Context, client *http.Client, webhookURL, secret string, card *Card) error {
+	payload := initCardMessage(card)
+	return push(ctx, client, webhookURL, secret, &payload.Signature, payload)
+}
+
+// push 为消息签名后推送到飞书的 webhook，飞书返回错误码时返回 *APIError
+func push(ctx context.Context, client *http.Client, webhookURL, secret string, signature *Signature, payload interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if secret != "" {
+		timestamp := time.Now().Unix()
+		sign, err := GenSign(secret, timestamp)
+		if err != nil {
+			return err
+		}
+		signature.Timestamp = strconv.FormatInt(timestamp, 10)
+		signature.Sign = sign
+	}
 
 	// 序列化为 JSON
 	messageBytes, err := json.Marshal(payload)
@@ -19,16 +51,33 @@ func PushToLark(webhookURL, message string) error {
 	}
 
 	// 发送 POST 请求到飞书的 webhook
-	resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(messageBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewBuffer(messageBytes))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("推送消息失败: %v", err)
 	}
 	defer resp.Body.Close()
 
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("推送失败: %s", resp.Status)
+	// 飞书在 HTTP 200 的响应中通过 code 返回错误，需要同时检查状态码和响应内容
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
 	}
+	return checkResponse(resp.StatusCode, body)
+}
+
+// GenSign 按飞书自定义机器人的签名校验规则生成签名：
+// 以 timestamp + "\n" + secret 作为密钥，对空字符串做 HmacSHA256 后进行 Base64 编码
+func GenSign(secret string, timestamp int64) (string, error) {
+	stringToSign := fmt.Sprintf("%v", timestamp) + "\n" + secret
 
-	return nil
+	h := hmac.New(sha256.New, []byte(stringToSign))
+	if _, err := h.Write([]byte{}); err != nil {
+		return "", fmt.Errorf("生成签名失败: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
 }
@@ -1,6 +1,13 @@
 package lark
 
+// Signature 是机器人开启签名校验时附加在消息中的时间戳和签名
+type Signature struct {
+	Timestamp string `json:"timestamp,omitempty"` // 时间戳（秒）
+	Sign      string `json:"sign,omitempty"`      // 签名
+}
+
 type SimpleMessage struct {
+	Signature
 	MsgType string `json:"msg_type"`
 	Content Text   `json:"content"`
 }
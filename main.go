@@ -4,110 +4,546 @@ import (
 	"code/config"
 	"code/db" // 引入 Redis 相关的包
 	"code/fetch"
-	"code/lark"
+	"code/health"
+	"code/notify"
+	"code/outbox"
 	"code/parse"
+	"code/route"
+	"code/schedule"
+	"code/translate"
+	"code/worker"
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
+	_ "time/tzdata" // 内置时区数据，运行镜像中没有安装 tzdata
 )
 
-const LarkWebHook = "https://open.feishu.cn/open-apis/bot/v2/hook/6710fb77-c813-4d32-b4a4-7a890a4d76db"
-
 func main() {
+	// 加载配置文件，密钥通过环境变量或密钥文件注入
+	config, err := config.LoadConfig("config/webconfig.yaml")
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
 	// 连接 Redis
-	client, err := db.NewDatabaseClient(db.RedisType)
+	client, err := db.NewDatabaseClient(db.RedisType, &db.RedisParam{
+		Addr:     config.Redis.Addr,
+		Password: config.Redis.Password,
+		DB:       config.Redis.DB,
+	})
 	if err != nil {
 		log.Fatalf("无法连接 Redis: %v", err)
 	}
 
-	// 加载配置文件
-	config, err := config.LoadConfig("config/webconfig.yaml")
+	// 收到 SIGINT/SIGTERM 时 ctx 被取消，停止调度新的抓取
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 命令行子命令，例如查看和重放死信
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, client, os.Args[1:]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	// 创建翻译器，整个运行期间复用；远程翻译服务的译文缓存在数据库中
+	translator, err := translate.New(config.Translate, config.TencentParams)
 	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+		log.Fatalf("创建翻译器失败: %v", err)
+	}
+	if translate.IsRemote(config.Translate.Provider) {
+		translator = translate.NewCached(translator, config.Translate.Provider, client, config.Translate.CacheTTL)
+	}
+
+	// 创建推送目的地
+	notifiers, err := notify.NewAll(config.Destinations)
+	if err != nil {
+		log.Fatalf("创建推送目的地失败: %v", err)
+	}
+
+	// 进行中的抓取、翻译和推送使用 workCtx，退出时等待它们完成，超过 shutdown_timeout 后才取消
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+
+	// 指标服务，推送限流等指标通过 /debug/vars 暴露
+	if config.MetricsAddr != "" {
+		go serveMetrics(ctx, config.MetricsAddr)
+	}
+
+	// 推送队列，失败的消息在后台按指数退避重试；退出时先停止后台推送，再由 scheduleFetch 推送剩余的消息
+	deliveries := outbox.NewOutbox(client, notifiers, config.Delivery)
+	runCtx, cancelRun := context.WithCancel(workCtx)
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		deliveries.Run(runCtx)
+	}()
+	stopRun := func() {
+		cancelRun()
+		<-runDone
 	}
 
-	// 调用 scheduleFetch 函数，设置每 2 分钟执行一次
-	scheduleFetch(config, 2*time.Minute, client)
+	// 推送路由，按站点过滤规则以及路由规则的站点、标签和关键词选择目的地
+	router, err := route.NewRouter(config)
+	if err != nil {
+		log.Fatalf("创建推送路由失败: %v", err)
+	}
+
+	// 站点处理任务池，限制并发抓取的站点数
+	pool := worker.NewPool(config.Concurrency)
+
+	// 站点健康状态追踪，持续失败的站点退避抓取并告警
+	tracker := health.NewTracker(client, config.Health)
+
+	// 按站点各自的抓取间隔或 cron 表达式调度，收到退出信号后等待进行中的任务完成
+	drained := scheduleFetch(ctx, workCtx, config, pool, client, tracker, translator, router, deliveries, stopRun)
+	cancelWork()
+
+	// 中止后再等待一小段时间，让已发出的推送返回并从队列中删除，避免下次启动重复推送
+	select {
+	case <-drained:
+	case <-time.After(shutdownGrace):
+		log.Printf("中止后等待超过 %s, 强制退出\n", shutdownGrace)
+	}
+	log.Println("已退出")
 }
 
-// scheduleFetch 每隔指定时间执行一次抓取和处理操作
-func scheduleFetch(config *config.Config, interval time.Duration, client db.DatabaseClient) {
-	// 设置一个定时器，每次触发间隔为 interval（例如 15 分钟）
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// shutdownGrace 是超过 shutdown_timeout 中止进行中的任务后，等待它们返回的时间
+const shutdownGrace = 5 * time.Second
 
-	// 首次执行任务
-	ProcessSites(config, client)
+// serveMetrics 启动指标服务，expvar 在默认的 ServeMux 上注册了 /debug/vars；ctx 取消时关闭
+func serveMetrics(ctx context.Context, addr string) {
+	server := &http.Server{Addr: addr}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
 
-	// 使用 for range 监听 ticker.C，避免手动使用 select{}
-	for range ticker.C {
-		log.Println("开始执行定时任务...")
-		ProcessSites(config, client) // 执行网站抓取和处理操作
+	log.Printf("指标服务监听 %s\n", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("指标服务退出: %v\n", err)
 	}
 }
 
-// ProcessSites 遍历配置中的每个站点，抓取网页内容并发送到 Lark
-func ProcessSites(config *config.Config, client db.DatabaseClient) {
-	// 循环遍历配置文件中的每个站点
-	for _, site := range config.Sites {
-		// 获取网站的 BaseURL
-		url := site.BaseURL
+// scheduleFetch 按站点各自的 interval 或 cron 调度抓取和处理操作，启动时所有站点先执行一次；
+// ctx 取消后停止调度，调用 stopRun 停止后台推送，等待进行中的站点处理完成后推送已入队的消息，最多等待 shutdown_timeout；
+// 返回的 channel 在这些任务全部结束后关闭
+func scheduleFetch(ctx, workCtx context.Context, cfg *config.Config, pool *worker.Pool, client db.DatabaseClient, tracker *health.Tracker, translator translate.Translator, router *route.Router, deliveries *outbox.Outbox, stopRun func()) <-chan struct{} {
+	scheduler, err := schedule.New(cfg.Sites, cfg.Location(), func(sites []config.SiteConfig) {
+		ProcessSites(workCtx, cfg, sites, pool, client, tracker, translator, router, deliveries)
+	})
+	if err != nil {
+		log.Fatalf("创建调度器失败: %v", err)
+	}
+	scheduler.Run(ctx)
 
-		// 使用 Fetch 函数获取网页内容
-		utf8Body, err := fetch.Fetch(url, 30*time.Second)
-		if err != nil {
-			log.Printf("Error fetching URL %s: %v\n", url, err)
-			continue // 如果抓取失败，继续下一个 URL
-		}
+	log.Printf("收到退出信号, 等待进行中的抓取和推送完成 (最多 %s)...\n", cfg.ShutdownTimeout)
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		// 后台推送退出后再做最后一次推送，退出过程中不会有两个推送同时进行
+		stopRun()
+		scheduler.Wait()
+		deliveries.Dispatch(workCtx)
+	}()
 
-		// fmt.Printf("%v", utf8Body)
+	select {
+	case <-drained:
+		log.Println("进行中的任务已完成")
+	case <-time.After(cfg.ShutdownTimeout):
+		log.Printf("等待超过 %s, 中止进行中的任务, 未推送的消息保留在队列中\n", cfg.ShutdownTimeout)
+	}
+	return drained
+}
 
-		// 解析网页内容，使用 Colly 解析内容
-		result, err := parse.Parse(utf8Body, site)
-		if err != nil {
-			log.Printf("Error parsing content from URL %s: %v\n", url, err)
-			continue // 如果解析失败，继续下一个 URL
+// ProcessSites 使用有并发上限的任务池处理到期的站点，同一域名的站点串行抓取，同一站点不会重叠执行；
+// 每个站点内部按顺序推送，等待所有站点完成或超过 tick_timeout
+func ProcessSites(ctx context.Context, config *config.Config, sites []config.SiteConfig, pool *worker.Pool, client db.DatabaseClient, tracker *health.Tracker, translator translate.Translator, router *route.Router, deliveries *outbox.Outbox) {
+	tasks := make([]worker.Task, 0, len(sites))
+	for _, site := range sites {
+		tasks = append(tasks, worker.Task{
+			Key:    site.Name,
+			Domain: siteDomain(site),
+			Run: func() {
+				processSite(ctx, config, site, client, tracker, translator, router, deliveries)
+			},
+		})
+	}
+
+	if pending := pool.RunAll(tasks, config.TickTimeout); len(pending) > 0 {
+		log.Printf("抓取超过 %s, 仍未完成的站点: %v\n", config.TickTimeout, pending)
+	}
+}
+
+// processSite 抓取单个站点的内容，将新条目按发布时间顺序放入推送队列
+// 连续失败的站点按指数退避推迟抓取，熔断期间只按 health.max_backoff 探测
+func processSite(ctx context.Context, config *config.Config, site config.SiteConfig, client db.DatabaseClient, tracker *health.Tracker, translator translate.Translator, router *route.Router, deliveries *outbox.Outbox) {
+	// 获取网站的 BaseURL
+	url := site.BaseURL
+
+	// 退避或熔断期间跳过该站点
+	if ok, _ := tracker.Allow(ctx, site.Name, time.Now()); !ok {
+		return
+	}
+
+	// 按站点类型获取网页或接口内容，带上次的 ETag / Last-Modified 发起条件请求
+	state := db.LoadFetchState(ctx, client, site.Name, siteURL(site))
+	resp, err := fetchSite(ctx, site, state)
+	if err != nil {
+		log.Printf("Error fetching URL %s: %v\n", url, err)
+		recordFailure(ctx, config, tracker, deliveries, site, err)
+		return // 如果抓取失败，跳过该站点
+	}
+
+	// 服务器返回 304 或内容哈希与上次相同时，跳过解析、翻译和去重
+	if resp.NotModified || (state.Hash != "" && resp.Hash == state.Hash) {
+		log.Printf("跳过站点 %s, 因为页面内容未变化\n", site.Name)
+		recordSuccess(ctx, config, tracker, deliveries, site)
+		if resp.ETag != state.ETag || resp.LastModified != state.LastModified {
+			resp.Hash = state.Hash
+			saveFetchState(ctx, client, site, resp)
 		}
+		return
+	}
 
-		// 检查 Redis 中是否已有该站点的内容
-		existingEndpoint, err := client.GetKey(site.Name) // 获取 Redis 中存储的值
-		if err == nil && existingEndpoint == result.Endpoint {
-			// 如果 Redis 中已有相同的 Endpoint，跳过处理
-			log.Printf("跳过站点 %s, 因为内容已存在\n", site.Name)
-			continue
+	// fmt.Printf("%v", resp.Body)
+
+	// 解析网页内容，提取列表页中的所有条目
+	results, err := parse.ParseAll(ctx, resp.Body, site)
+	if err != nil {
+		log.Printf("Error parsing content from URL %s: %v\n", url, err)
+		recordFailure(ctx, config, tracker, deliveries, site, err)
+		return // 如果解析失败，跳过该站点
+	}
+	recordSuccess(ctx, config, tracker, deliveries, site)
+
+	// 丢弃超过时效的条目
+	results = dropStale(site, results, time.Now())
+
+	// 过滤出尚未推送过的条目
+	newResults, err := filterUnseen(ctx, client, site.Name, results, config.SeenRetention)
+	if err != nil {
+		log.Printf("Error checking seen items for site %s: %v\n", site.Name, err)
+		return
+	}
+	if len(newResults) == 0 {
+		log.Printf("跳过站点 %s, 因为内容已存在\n", site.Name)
+		saveFetchState(ctx, client, site, resp)
+		return
+	}
+
+	// 按发布时间从旧到新依次推送
+	failed := false
+	for _, result := range sortChronologically(newResults) {
+		// 去重在翻译之前完成，只有新条目才会翻译；指纹基于原始标题
+		fingerprint := db.Fingerprint(result.Endpoint, result.OriginalTitle)
+		translateTitle(ctx, translator, site, &result, config.Translate.TargetLang)
+
+		// 按站点过滤规则和路由规则选择推送目的地，被过滤或没有匹配的目的地时只记入已见集合
+		decision := router.Route(site, result)
+		if decision.Filtered {
+			log.Printf("条目未通过站点 %s 的过滤规则, 跳过: %s\n", site.Name, result.Title)
+		} else if len(decision.Destinations) == 0 {
+			log.Printf("条目没有匹配的推送路由, 跳过: %s\n", result.Endpoint)
 		}
 
-		// 使用 fmt.Sprintf 创建消息
-		// message := fmt.Sprintf("%s\n最新消息:\n%s\n链接: %s\n日期: %s", site.Name, result.Title, result.Endpoint, result.Date.Format("2006-01-02"))
-		// 使用 fmt.Sprintf 创建消息，添加分隔符和突出显示的格式
-		message := fmt.Sprintf(
-			"【%s】\n\n"+ // 网站名称，突出显示
-				"📢 最新消息:\n"+ // 添加提醒符号
-				"➡️ %s\n\n"+ // 标题，使用箭头突出显示
-				"🔗 链接: %s\n\n"+ // 链接行
-				"📅 日期: %s", // 日期行
-			site.Name,
-			result.Title,
-			result.Endpoint,
-			result.Date.Format("2006-01-02"),
-		)
-
-		// 发送消息到 Lark
-		err = lark.PushToLark(LarkWebHook, message)
-		if err != nil {
-			log.Printf("Error sending message to Lark for URL %s: %v\n", url, err)
-			continue // 如果发送失败，继续下一个 URL
+		item := newItem(site, result, config.Location())
+		item.Keywords = decision.Keywords
+
+		// 为各推送目的地写入推送队列，推送失败由队列重试，写入失败时停止处理该站点的后续条目，保证顺序
+		if err := deliveries.Enqueue(ctx, item, decision.Destinations); err != nil {
+			log.Printf("Error enqueueing message for URL %s: %v\n", item.Link, err)
+			failed = true
+			break
 		}
 
-		// 将新的 Endpoint 存入 Redis
-		err = client.SetKey(site.Name, result.Endpoint)
+		// 条目已进入推送队列，将指纹记入已见集合
+		// 条目已入队，即使正在退出也要记录，避免下次启动重复入队
+		err = client.MarkSeen(context.WithoutCancel(ctx), site.Name, fingerprint, config.SeenRetention)
 		if err != nil {
 			log.Printf("Error saving data to Redis for site %s: %v\n", site.Name, err)
-			continue
+			failed = true
+			break
 		}
 
 		// 打印成功的结果
-		log.Printf("Successfully processed URL: %s\n", url)
+		log.Printf("Successfully processed URL: %s\n", result.Endpoint)
 		fmt.Println(result)
 	}
+
+	// 全部条目入队后才记录抓取状态，否则内容未变化时剩余的条目会一直被跳过
+	if !failed {
+		saveFetchState(ctx, client, site, resp)
+	}
+
+	// 通知推送队列尽快推送本站点的新条目，推送在后台进行，不占用抓取任务
+	deliveries.Trigger()
+}
+
+// recordFailure 记录站点的抓取失败，熔断器打开时向告警目的地发送一次故障告警；退出导致的失败不计入
+func recordFailure(ctx context.Context, cfg *config.Config, tracker *health.Tracker, deliveries *outbox.Outbox, site config.SiteConfig, cause error) {
+	if ctx.Err() != nil {
+		return
+	}
+	state, opened, err := tracker.Failure(ctx, site.Name, site.Interval, cause, time.Now())
+	if err != nil {
+		log.Printf("Error saving health state for site %s: %v\n", site.Name, err)
+	}
+	log.Printf("站点 %s 连续失败 %d 次, 下次抓取时间: %s\n", site.Name, state.ConsecutiveFailures, state.NextAttempt.In(cfg.Location()).Format("2006-01-02 15:04:05"))
+	if opened {
+		title := fmt.Sprintf("⚠️ 站点 %s 连续 %d 次抓取失败，已暂停抓取，每 %s 探测一次", site.Name, state.ConsecutiveFailures, cfg.Health.MaxBackoff)
+		sendAlert(ctx, cfg, deliveries, site, notify.CategoryAlert, title, state.LastError)
+	}
+}
+
+// recordSuccess 记录站点的抓取成功，站点从故障中恢复时向告警目的地发送恢复通知
+func recordSuccess(ctx context.Context, cfg *config.Config, tracker *health.Tracker, deliveries *outbox.Outbox, site config.SiteConfig) {
+	previous, recovered, err := tracker.Success(ctx, site.Name, time.Now())
+	if err != nil {
+		log.Printf("Error saving health state for site %s: %v\n", site.Name, err)
+	}
+	if recovered {
+		title := fmt.Sprintf("✅ 站点 %s 已恢复，此前连续 %d 次抓取失败", site.Name, previous.ConsecutiveFailures)
+		sendAlert(ctx, cfg, deliveries, site, notify.CategoryRecovered, title, previous.LastError)
+	}
+}
+
+// sendAlert 将站点故障或恢复告警放入推送队列，发往 health.alert_destinations
+func sendAlert(ctx context.Context, cfg *config.Config, deliveries *outbox.Outbox, site config.SiteConfig, category, title, lastError string) {
+	log.Printf("%s (最近一次错误: %s)\n", title, lastError)
+	if len(cfg.Health.AlertDestinations) == 0 {
+		return
+	}
+
+	now := time.Now()
+	item := notify.Item{
+		Site:          site.Name,
+		Category:      category,
+		Title:         title,
+		OriginalTitle: title,
+		Summary:       "最近一次错误: " + lastError,
+		Link:          site.BaseURL,
+		Date:          now,
+		DateText:      formatDate(now, cfg.Location()),
+	}
+	if err := deliveries.Enqueue(ctx, item, cfg.Health.AlertDestinations); err != nil {
+		log.Printf("Error enqueueing alert for site %s: %v\n", site.Name, err)
+		return
+	}
+	deliveries.Trigger()
+}
+
+// siteDomain 返回站点抓取地址的域名，无法解析时返回站点名称
+func siteDomain(site config.SiteConfig) string {
+	parsedURL, err := url.Parse(siteURL(site))
+	if err != nil || parsedURL.Host == "" {
+		return site.Name
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
+
+// translateTitle 翻译条目标题，译文写入 TranslatedTitle 和 Title，原标题保留在 OriginalTitle；翻译失败时保留原标题
+// 站点关闭了翻译，或站点声明的语言（未声明时按标题检测的语言）与目标语言相同时不调用翻译服务；
+// 站点声明了语言时以它作为翻译的源语言，否则由翻译服务自动识别
+func translateTitle(ctx context.Context, translator translate.Translator, site config.SiteConfig, result *parse.Result, targetLang string) {
+	result.DetectedLanguage = translate.DetectLanguage(result.OriginalTitle)
+	sourceLang := site.Language
+	if sourceLang == "" {
+		sourceLang = result.DetectedLanguage
+	}
+	if !site.TranslateEnabled() || translate.SameLanguage(sourceLang, targetLang) {
+		return
+	}
+
+	translatedTitle, err := translator.Translate(ctx, result.OriginalTitle, site.Language, targetLang)
+	if err != nil {
+		log.Printf("标题翻译失败: %v", err)
+		return
+	}
+	result.TranslatedTitle = translatedTitle
+	result.Title = translatedTitle
+}
+
+// newItem 将解析结果转换为待推送的条目
+func newItem(site config.SiteConfig, result parse.Result, loc *time.Location) notify.Item {
+	return notify.Item{
+		Site:            site.Name,
+		Category:        site.Category,
+		Title:           result.Title,
+		OriginalTitle:   result.OriginalTitle,
+		TranslatedTitle: result.TranslatedTitle,
+		Link:            result.Endpoint,
+		Date:            result.Date,
+		DateText:        formatDate(result.Date, loc),
+	}
+}
+
+// dropStale 丢弃发布时间早于站点 max_age 的条目，没有日期的条目予以保留；
+// 过期条目不会记入已见集合，每次抓取都会再次出现，因此每个站点只记录一行汇总日志
+func dropStale(site config.SiteConfig, results []parse.Result, now time.Time) []parse.Result {
+	cutoff := now.Add(-site.MaxAge)
+	fresh := results[:0:0]
+	var oldest time.Time
+	for _, result := range results {
+		if !result.Date.IsZero() && result.Date.Before(cutoff) {
+			if oldest.IsZero() || result.Date.Before(oldest) {
+				oldest = result.Date
+			}
+			continue
+		}
+		fresh = append(fresh, result)
+	}
+	if dropped := len(results) - len(fresh); dropped > 0 {
+		log.Printf("丢弃站点 %s 的 %d 条过期条目 (超过 %s, 最早 %s)\n", site.Name, dropped, site.MaxAge, oldest.Format(time.RFC3339))
+	}
+	return fresh
+}
+
+// formatDate 将条目日期转换为团队时区显示；只有日期没有时刻的条目直接显示日期，避免跨时区换算后日期错位
+func formatDate(date time.Time, loc *time.Location) string {
+	if date.IsZero() {
+		return "未知"
+	}
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02")
+	}
+	return date.In(loc).Format("2006-01-02 15:04")
+}
+
+// siteURL 返回站点实际抓取的地址，json 类型站点优先使用 api.url
+func siteURL(site config.SiteConfig) string {
+	if site.Type == config.SiteTypeJSON && site.API != nil && site.API.URL != "" {
+		return site.API.URL
+	}
+	return site.BaseURL
+}
+
+// fetchSite 按站点类型抓取内容，json 类型站点按 api 配置发起请求；state 中的 ETag / Last-Modified 用于发起条件请求
+// 网页内容会转换为 UTF-8，内容未变化（304）时 Body 为空
+func fetchSite(ctx context.Context, site config.SiteConfig, state db.FetchState) (*fetch.Response, error) {
+	req := fetch.Request{URL: siteURL(site), ETag: state.ETag, LastModified: state.LastModified}
+	if site.Type == config.SiteTypeJSON && site.API != nil {
+		req.Method = site.API.Method
+		req.Headers = site.API.Headers
+		req.Body = site.API.Body
+		return fetch.FetchResponse(ctx, req, 30*time.Second)
+	}
+
+	resp, err := fetch.FetchResponse(ctx, req, 30*time.Second)
+	// 订阅源的编码由 XML 声明决定，交给解析器转换，不按 HTML 的 meta 标签转换
+	if err != nil || resp.NotModified || site.Type == config.SiteTypeFeed {
+		return resp, err
+	}
+	resp.Body, err = fetch.DecodeHTML(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// saveFetchState 在站点内容处理完成后保存抓取状态，下次抓取时据此发起条件请求并跳过未变化的内容
+func saveFetchState(ctx context.Context, client db.DatabaseClient, site config.SiteConfig, resp *fetch.Response) {
+	state := db.FetchState{
+		URL:          siteURL(site),
+		ETag:         resp.ETag,
+		LastModified: resp.LastModified,
+		Hash:         resp.Hash,
+		UpdatedAt:    time.Now(),
+	}
+	// 条目已入队，即使正在退出也要记录
+	if err := db.SaveFetchState(context.WithoutCancel(ctx), client, site.Name, state); err != nil {
+		log.Printf("Error saving fetch state for site %s: %v\n", site.Name, err)
+	}
+}
+
+// filterUnseen 返回已见集合中没有记录的条目（页面顺序），同一页中重复出现的条目只保留一次
+// 站点的已见集合为空时（首次运行或旧版单键记录迁移），先把旧记录之前的历史条目记入集合
+func filterUnseen(ctx context.Context, client db.DatabaseClient, site string, results []parse.Result, retention time.Duration) ([]parse.Result, error) {
+	count, err := client.CountSeen(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		lastEndpoint, err := client.GetKey(ctx, site)
+		hasLast := err == nil
+		newResults := unseenResults(results, lastEndpoint, hasLast)
+		for _, result := range results[len(newResults):] {
+			if err := client.MarkSeen(ctx, site, db.Fingerprint(result.Endpoint, result.OriginalTitle), retention); err != nil {
+				return nil, err
+			}
+		}
+		// 集合已写入历史条目后删除旧版记录，迁移只进行一次；旧记录指向的条目已不在页面上时本页全部视为新条目，推送后同样会写入集合
+		if hasLast {
+			if err := client.DeleteKey(ctx, site); err != nil {
+				log.Printf("Error deleting legacy record for site %s: %v\n", site, err)
+			}
+		}
+		log.Printf("站点 %s 已见集合初始化完成, 记录 %d 条历史条目\n", site, len(results)-len(newResults))
+		return newResults, nil
+	}
+
+	var newResults []parse.Result
+	var seenFingerprints []string
+	checked := make(map[string]bool)
+	for _, result := range results {
+		fingerprint := db.Fingerprint(result.Endpoint, result.OriginalTitle)
+		if checked[fingerprint] {
+			continue
+		}
+		checked[fingerprint] = true
+		seen, err := client.IsSeen(ctx, site, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if seen {
+			seenFingerprints = append(seenFingerprints, fingerprint)
+		} else {
+			newResults = append(newResults, result)
+		}
+	}
+
+	// 刷新仍在列表页上的条目的记录时间，避免长期没有更新的站点的历史条目被保留期清理后重复推送
+	if err := client.TouchSeen(ctx, site, seenFingerprints); err != nil {
+		return nil, err
+	}
+	return newResults, nil
+}
+
+// unseenResults 返回列表页中位于旧版单键记录（上次推送的 Endpoint）之前的新条目（页面顺序）
+// 没有旧记录时只返回最新的一条，避免一次性推送整页历史内容
+func unseenResults(results []parse.Result, lastEndpoint string, hasLast bool) []parse.Result {
+	if len(results) == 0 {
+		return nil
+	}
+	if !hasLast {
+		return results[:1]
+	}
+	for i, result := range results {
+		if result.Endpoint == lastEndpoint {
+			return results[:i]
+		}
+	}
+	// 上次推送的条目已不在列表中，说明期间发布的新内容超过了一页
+	return results
+}
+
+// sortChronologically 将页面顺序（从新到旧）的条目按发布时间从旧到新排序
+func sortChronologically(results []parse.Result) []parse.Result {
+	sorted := make([]parse.Result, len(results))
+	for i, result := range results {
+		sorted[len(results)-1-i] = result
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	return sorted
 }
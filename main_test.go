@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"code/db"
+	"code/db/dbtest"
+	"code/parse"
+)
+
+// pageResults 按页面顺序（从新到旧）构造条目，标题与链接最后一段相同
+func pageResults(names ...string) []parse.Result {
+	results := make([]parse.Result, len(names))
+	for i, name := range names {
+		results[i] = parse.Result{Title: name, OriginalTitle: name, Endpoint: "https://example.com/news/" + name}
+	}
+	return results
+}
+
+func titles(results []parse.Result) []string {
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Title)
+	}
+	return names
+}
+
+func TestFilterUnseenMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		legacy   string // 旧版单键记录，为空时没有记录
+		page     []string
+		wantNew  []string
+		wantSeen int
+	}{
+		{"旧记录在页面上", "https://example.com/news/c", []string{"a", "b", "c", "d"}, []string{"a", "b"}, 2},
+		{"旧记录是最新一条", "https://example.com/news/a", []string{"a", "b", "c"}, nil, 3},
+		{"旧记录不在页面上", "https://example.com/news/z", []string{"a", "b", "c"}, []string{"a", "b", "c"}, 0},
+		{"没有旧记录时只推送最新一条", "", []string{"a", "b", "c"}, []string{"a"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := dbtest.NewMemory()
+			if tt.legacy != "" {
+				client.SetKey(ctx, "site", tt.legacy)
+			}
+
+			got, err := filterUnseen(ctx, client, "site", pageResults(tt.page...), time.Hour)
+			if err != nil {
+				t.Fatalf("filterUnseen: %v", err)
+			}
+			if !slices.Equal(titles(got), tt.wantNew) {
+				t.Errorf("新条目 = %v, 期望 %v", titles(got), tt.wantNew)
+			}
+			if seen := client.Seen("site"); len(seen) != tt.wantSeen {
+				t.Errorf("已见集合有 %d 条, 期望 %d 条", len(seen), tt.wantSeen)
+			}
+			if _, err := client.GetKey(ctx, "site"); err == nil {
+				t.Error("迁移后应删除旧版记录")
+			}
+		})
+	}
+}
+
+func TestFilterUnseenUsesSeenSet(t *testing.T) {
+	ctx := context.Background()
+	client := dbtest.NewMemory()
+	for _, result := range pageResults("b", "d") {
+		client.MarkSeen(ctx, "site", db.Fingerprint(result.Endpoint, result.OriginalTitle), time.Hour)
+	}
+	// 旧版记录在已见集合非空时不再参与判断
+	client.SetKey(ctx, "site", "https://example.com/news/a")
+
+	results := append(pageResults("a", "b", "c", "d"), pageResults("a")...)
+	got, err := filterUnseen(ctx, client, "site", results, time.Hour)
+	if err != nil {
+		t.Fatalf("filterUnseen: %v", err)
+	}
+	if want := []string{"a", "c"}; !slices.Equal(titles(got), want) {
+		t.Errorf("新条目 = %v, 期望 %v", titles(got), want)
+	}
+}
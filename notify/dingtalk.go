@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DingTalk 是钉钉自定义机器人的 Notifier 实现
+type DingTalk struct {
+	webhookURL string
+	secret     string // 加签密钥，机器人未开启加签时为空
+	client     *http.Client
+}
+
+// NewDingTalk 创建钉钉自定义机器人推送
+func NewDingTalk(webhookURL, secret string, client *http.Client) (*DingTalk, error) {
+	if webhookURL == "" {
+		return nil, fmt.Errorf("钉钉推送缺少 webhook_url")
+	}
+	return &DingTalk{webhookURL: webhookURL, secret: secret, client: client}, nil
+}
+
+// Send 以文本消息推送到钉钉
+func (d *DingTalk) Send(ctx context.Context, item Item) error {
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": FormatText(item)},
+	}
+
+	webhookURL, err := d.signedURL(time.Now())
+	if err != nil {
+		return err
+	}
+
+	body, err := postJSON(ctx, d.client, webhookURL, payload, nil)
+	if err != nil {
+		return err
+	}
+	return checkErrCode("钉钉", body)
+}
+
+// signedURL 按钉钉加签规则在 webhook 地址上附加 timestamp 和 sign 参数
+func (d *DingTalk) signedURL(now time.Time) (string, error) {
+	if d.secret == "" {
+		return d.webhookURL, nil
+	}
+
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(d.secret))
+	mac.Write([]byte(timestamp + "\n" + d.secret))
+	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	parsedURL, err := url.Parse(d.webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("webhook_url 解析错误: %v", err)
+	}
+	query := parsedURL.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", sign)
+	parsedURL.RawQuery = query.Encode()
+	return parsedURL.String(), nil
+}
+
+// checkErrCode 检查钉钉、企业微信返回的 errcode，非 0 时返回错误
+func checkErrCode(name string, body []byte) error {
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("%s响应解析失败: %v", name, err)
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("%s推送失败: errcode=%d errmsg=%s", name, resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"code/lark"
+)
+
+// 飞书消息格式
+const (
+	LarkFormatCard = "card" // 消息卡片
+	LarkFormatText = "text" // 纯文本消息
+)
+
+// Lark 是飞书自定义机器人的 Notifier 实现
+type Lark struct {
+	webhookURL string
+	secret     string            // 签名校验密钥，机器人未开启签名校验时为空
+	format     string            // 消息格式，card 或 text
+	colors     map[string]string // 分类到卡片标题栏颜色的映射，覆盖内置配色
+	client     *http.Client
+
+	// requestWait 在卡片被拒绝、改发文本消息前调用，由限流器注入，使每个请求都计入频率限制
+	requestWait func(ctx context.Context) error
+}
+
+// NewLark 创建飞书自定义机器人推送，format 为空时使用消息卡片
+func NewLark(webhookURL, secret, format string, colors map[string]string, client *http.Client) (*Lark, error) {
+	if webhookURL == "" {
+		return nil, fmt.Errorf("飞书推送缺少 webhook_url")
+	}
+	switch format {
+	case "":
+		format = LarkFormatCard
+	case LarkFormatCard, LarkFormatText:
+	default:
+		return nil, fmt.Errorf("不支持的飞书消息格式: %s", format)
+	}
+	return &Lark{webhookURL: webhookURL, secret: secret, format: format, colors: colors, client: client}, nil
+}
+
+// Send 以消息卡片推送到飞书，卡片内容被拒绝时退回文本消息
+func (l *Lark) Send(ctx context.Context, item Item) error {
+	if l.format == LarkFormatCard {
+		err := lark.PushCardToLark(ctx, l.client, l.webhookURL, l.secret, l.card(item))
+		if !fallbackToText(err) {
+			return err
+		}
+		log.Printf("飞书卡片推送失败，改用文本消息: %v\n", err)
+		if l.requestWait != nil {
+			if err := l.requestWait(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return lark.PushToLark(ctx, l.client, l.webhookURL, l.secret, FormatText(item))
+}
+
+// setRequestWait 实现 multiRequester 接口
+func (l *Lark) setRequestWait(wait func(ctx context.Context) error) {
+	l.requestWait = wait
+}
+
+// fallbackToText 返回卡片推送失败后是否应改用文本消息：
+// 网络错误、限流以及签名、IP 白名单错误与消息格式无关，改用文本消息也会失败
+func fallbackToText(err error) bool {
+	var apiErr *lark.APIError
+	if !errors.As(err, &apiErr) || apiErr.Retryable() {
+		return false
+	}
+	return apiErr.Code != lark.CodeSignMismatch && apiErr.Code != lark.CodeIPNotAllowed
+}
+
+// card 根据条目生成新闻卡片
+func (l *Lark) card(item Item) *lark.Card {
+	return lark.BuildNewsCard(lark.NewsCard{
+		Site:            item.Site,
+		Category:        item.Category,
+		Template:        l.colors[strings.ToLower(item.Category)],
+		Title:           item.Title,
+		OriginalTitle:   item.OriginalTitle,
+		TranslatedTitle: item.TranslatedTitle,
+		Link:            item.Link,
+		Date:            item.DateText,
+		Keywords:        item.Keywords,
+		Summary:         item.Summary,
+	})
+}
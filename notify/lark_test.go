@@ -0,0 +1,241 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"code/lark"
+)
+
+// larkRequest 是测试服务器收到的飞书 webhook 请求
+type larkRequest struct {
+	MsgType   string `json:"msg_type"`
+	Timestamp string `json:"timestamp"`
+	Sign      string `json:"sign"`
+}
+
+// larkServer 记录收到的请求，并按消息类型返回预设的响应
+type larkServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requests  []larkRequest
+	responses map[string]string // 消息类型到响应内容的映射，未设置时返回成功
+	status    int               // HTTP 状态码，为 0 时返回 200
+}
+
+func newLarkServer(t *testing.T) *larkServer {
+	s := &larkServer{responses: make(map[string]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req larkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("请求内容无法解析: %v", err)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		body, ok := s.responses[req.MsgType]
+		s.mu.Unlock()
+		if !ok {
+			body = `{"code":0,"msg":"success"}`
+		}
+		if s.status != 0 {
+			w.WriteHeader(s.status)
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *larkServer) msgTypes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var types []string
+	for _, req := range s.requests {
+		types = append(types, req.MsgType)
+	}
+	return types
+}
+
+func newTestLark(t *testing.T, url, secret, format string) *Lark {
+	l, err := NewLark(url, secret, format, nil, &http.Client{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewLark: %v", err)
+	}
+	return l
+}
+
+var testItem = Item{
+	Site:          "NVIDIA",
+	Category:      "tech",
+	Title:         "NVIDIA announces new GPU",
+	OriginalTitle: "NVIDIA announces new GPU",
+	Link:          "https://example.com/news/1",
+	DateText:      "2024-05-01",
+}
+
+func TestLarkSignsRequests(t *testing.T) {
+	server := newLarkServer(t)
+	const secret = "test-secret"
+
+	if err := newTestLark(t, server.URL, secret, "").Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if len(server.requests) != 1 {
+		t.Fatalf("收到 %d 个请求, 期望 1 个", len(server.requests))
+	}
+	req := server.requests[0]
+	if req.MsgType != "interactive" {
+		t.Errorf("msg_type = %q, 默认应推送消息卡片", req.MsgType)
+	}
+	timestamp, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp %q 无效: %v", req.Timestamp, err)
+	}
+	if d := time.Since(time.Unix(timestamp, 0)); d < -time.Minute || d > time.Minute {
+		t.Errorf("timestamp 与当前时间相差 %s", d)
+	}
+	want, err := lark.GenSign(secret, timestamp)
+	if err != nil {
+		t.Fatalf("GenSign: %v", err)
+	}
+	if req.Sign != want {
+		t.Errorf("sign = %q, 期望 %q", req.Sign, want)
+	}
+}
+
+func TestLarkWithoutSecretSendsNoSignature(t *testing.T) {
+	server := newLarkServer(t)
+
+	if err := newTestLark(t, server.URL, "", LarkFormatText).Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := server.requests[0]
+	if req.MsgType != "text" {
+		t.Errorf("msg_type = %q, 期望 text", req.MsgType)
+	}
+	if req.Timestamp != "" || req.Sign != "" {
+		t.Errorf("未配置 secret 时不应签名, timestamp=%q sign=%q", req.Timestamp, req.Sign)
+	}
+}
+
+func TestLarkCardFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		card      string // 卡片请求的响应
+		wantTypes []string
+		wantErr   bool
+	}{
+		{"卡片被拒绝时改发文本", `{"code":11246,"msg":"card content invalid"}`, []string{"interactive", "text"}, false},
+		{"缺少关键词时改发文本", `{"code":19024,"msg":"Key Words Not Found"}`, []string{"interactive", "text"}, false},
+		{"签名错误不改发", `{"code":19021,"msg":"sign match fail"}`, []string{"interactive"}, true},
+		{"IP 不在白名单不改发", `{"code":19022,"msg":"ip not allowed"}`, []string{"interactive"}, true},
+		{"限流不改发", `{"code":9499,"msg":"too many request"}`, []string{"interactive"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLarkServer(t)
+			server.responses["interactive"] = tt.card
+
+			err := newTestLark(t, server.URL, "", LarkFormatCard).Send(context.Background(), testItem)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send 错误 = %v, 期望出错 %v", err, tt.wantErr)
+			}
+			if got := server.msgTypes(); !slices.Equal(got, tt.wantTypes) {
+				t.Errorf("请求的消息类型 = %v, 期望 %v", got, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestLarkFallbackWaitsForRateLimit(t *testing.T) {
+	server := newLarkServer(t)
+	server.responses["interactive"] = `{"code":11246,"msg":"card content invalid"}`
+
+	l := newTestLark(t, server.URL, "", LarkFormatCard)
+	waits := 0
+	l.setRequestWait(func(ctx context.Context) error {
+		waits++
+		return nil
+	})
+	if err := l.Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if waits != 1 {
+		t.Errorf("改发文本前取令牌 %d 次, 期望 1 次", waits)
+	}
+}
+
+func TestLarkTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	l, err := NewLark(server.URL, "", LarkFormatText, nil, &http.Client{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewLark: %v", err)
+	}
+	err = l.Send(context.Background(), testItem)
+	if err == nil {
+		t.Fatal("请求超时后应返回错误")
+	}
+	if !IsRetryable(err) {
+		t.Errorf("超时错误应可以重试: %v", err)
+	}
+}
+
+func TestLarkRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantCode  int
+		retryable bool
+	}{
+		{"限流", http.StatusOK, `{"code":9499,"msg":"too many request"}`, lark.CodeRateLimited, true},
+		{"频率超限", http.StatusOK, `{"code":11232,"msg":"frequency limited"}`, lark.CodeFrequencyLimited, true},
+		{"签名错误", http.StatusOK, `{"code":19021,"msg":"sign match fail"}`, lark.CodeSignMismatch, false},
+		{"缺少关键词", http.StatusOK, `{"code":19024,"msg":"Key Words Not Found"}`, lark.CodeKeywordNotFound, false},
+		{"旧版响应格式", http.StatusOK, `{"StatusCode":19021,"StatusMessage":"sign match fail"}`, lark.CodeSignMismatch, false},
+		{"HTTP 429", http.StatusTooManyRequests, `too many requests`, 0, true},
+		{"HTTP 502", http.StatusBadGateway, `bad gateway`, 0, true},
+		{"HTTP 400", http.StatusBadRequest, `bad request`, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLarkServer(t)
+			server.status = tt.status
+			server.responses["text"] = tt.body
+
+			err := newTestLark(t, server.URL, "", LarkFormatText).Send(context.Background(), testItem)
+			var apiErr *lark.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Send 错误 = %v, 期望 *lark.APIError", err)
+			}
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("Code = %d, 期望 %d", apiErr.Code, tt.wantCode)
+			}
+			if got := IsRetryable(err); got != tt.retryable {
+				t.Errorf("IsRetryable = %v, 期望 %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	if IsRetryable(nil) {
+		t.Error("nil 不应重试")
+	}
+	if !IsRetryable(errors.New("connection refused")) {
+		t.Error("网络错误应可以重试")
+	}
+}
@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"code/config"
+	"code/filter"
+	"code/lark"
+)
+
+// Item 是一条待推送的新闻
+type Item struct {
+	Site            string    `json:"site"`
+	Category        string    `json:"category"`         // 站点分类
+	Title           string    `json:"title"`            // 显示的标题，翻译后为译文
+	OriginalTitle   string    `json:"original_title"`   // 页面上的原始标题
+	TranslatedTitle string    `json:"translated_title"` // 标题译文，未翻译时为空
+	Link            string    `json:"link"`
+	Date            time.Time `json:"date"`
+	DateText        string    `json:"date_text"` // 按团队时区格式化后的日期
+	Keywords        []string  `json:"keywords"`  // 标题中命中过滤规则的关键词，推送时高亮显示
+	Summary         string    `json:"summary"`   // 附加说明，例如站点故障告警中的错误信息
+}
+
+// Notifier 是通用的消息推送接口
+type Notifier interface {
+	// Send 推送一条新闻，失败时返回错误
+	Send(ctx context.Context, item Item) error
+}
+
+// 支持的推送渠道
+const (
+	TypeLark     = "lark"
+	TypeDingTalk = "dingtalk"
+	TypeWeCom    = "wecom"
+	TypeSlack    = "slack"
+	TypeTelegram = "telegram"
+	TypeWebhook  = "webhook" // 通用 JSON webhook
+)
+
+// 站点健康通知使用的分类
+const (
+	CategoryAlert     = "alert"     // 站点故障告警
+	CategoryRecovered = "recovered" // 站点恢复通知
+)
+
+// IsRetryable 返回推送错误是否可以重试；目前只有飞书能区分可重试与不可重试的错误，其他渠道的错误都视为可以重试
+func IsRetryable(err error) bool {
+	return lark.IsRetryable(err)
+}
+
+// defaultTimeout 是推送请求的默认超时
+const defaultTimeout = 10 * time.Second
+
+// New 根据推送目的地配置返回相应的 Notifier 实现，推送频率按渠道限制
+func New(cfg config.DestinationConfig) (Notifier, error) {
+	notifier, err := newNotifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return withRateLimit(cfg, notifier), nil
+}
+
+// newNotifier 根据推送渠道类型创建 Notifier
+func newNotifier(cfg config.DestinationConfig) (Notifier, error) {
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+	if cfg.Timeout <= 0 {
+		httpClient.Timeout = defaultTimeout
+	}
+
+	switch cfg.Type {
+	case TypeLark:
+		return NewLark(cfg.WebhookURL, cfg.Secret, cfg.Format, cfg.Colors, httpClient)
+	case TypeDingTalk:
+		return NewDingTalk(cfg.WebhookURL, cfg.Secret, httpClient)
+	case TypeWeCom:
+		return NewWeCom(cfg.WebhookURL, httpClient)
+	case TypeSlack:
+		return NewSlack(cfg.WebhookURL, httpClient)
+	case TypeTelegram:
+		return NewTelegram(cfg.APIURL, cfg.BotToken, cfg.ChatID, httpClient)
+	case TypeWebhook:
+		return NewWebhook(cfg.WebhookURL, cfg.Headers, httpClient)
+	default:
+		return nil, fmt.Errorf("不支持的推送渠道类型: %s", cfg.Type)
+	}
+}
+
+// NewAll 为每个推送目的地创建 Notifier，以目的地名称为键
+func NewAll(cfgs []config.DestinationConfig) (map[string]Notifier, error) {
+	notifiers := make(map[string]Notifier, len(cfgs))
+	for _, cfg := range cfgs {
+		notifier, err := New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("创建推送目的地 %s 失败: %v", cfg.Name, err)
+		}
+		notifiers[cfg.Name] = notifier
+	}
+	return notifiers, nil
+}
+
+// FormatText 创建纯文本消息，添加分隔符和突出显示的格式；标题经过翻译且与原文不同时同时显示原文
+func FormatText(item Item) string {
+	title := "➡️ " + highlightText(item.Title, item.Keywords) // 标题，使用箭头突出显示
+	if item.TranslatedTitle != "" && item.TranslatedTitle != item.OriginalTitle {
+		title = fmt.Sprintf("➡️ %s\n📝 原文: %s", highlightText(item.TranslatedTitle, item.Keywords), highlightText(item.OriginalTitle, item.Keywords))
+	}
+	if len(item.Keywords) > 0 {
+		title += "\n🏷️ 关键词: " + strings.Join(item.Keywords, ", ")
+	}
+	if item.Summary != "" {
+		title += "\n📝 " + item.Summary
+	}
+
+	return fmt.Sprintf(
+		"【%s】\n\n"+ // 网站名称，突出显示
+			"📢 最新消息:\n"+ // 添加提醒符号
+			"%s\n\n"+ // 标题（及原文）
+			"🔗 链接: %s\n\n"+ // 链接行
+			"📅 日期: %s", // 日期行
+		item.Site,
+		title,
+		item.Link,
+		item.DateText,
+	)
+}
+
+// highlightText 在纯文本中用「」标出关键词
+func highlightText(text string, keywords []string) string {
+	return filter.Highlight(text, keywords, func(keyword string) string {
+		return "「" + keyword + "」"
+	}, func(text string) string {
+		return text
+	})
+}
+
+// postJSON 以 JSON 格式发送 POST 请求，返回响应体；HTTP 状态码不是 2xx 时返回错误
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("消息序列化失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("推送消息失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("推送失败: %s %s", resp.Status, respBody)
+	}
+	return respBody, nil
+}
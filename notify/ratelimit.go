@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"code/config"
+)
+
+// defaultRateLimits 是各推送渠道的默认频率限制，未列出的渠道默认不限制
+var defaultRateLimits = map[string][]config.RateLimit{
+	TypeLark:     {{Requests: 5, Per: time.Second}, {Requests: 100, Per: time.Minute}},
+	TypeDingTalk: {{Requests: 20, Per: time.Minute}},
+	TypeWeCom:    {{Requests: 20, Per: time.Minute}},
+	TypeSlack:    {{Requests: 1, Per: time.Second}},
+	TypeTelegram: {{Requests: 20, Per: time.Minute}},
+}
+
+// throttleMetrics 记录各推送目的地因限流推迟或等待的次数、等待总时长和推送成功的消息数，通过 expvar 的 /debug/vars 暴露
+var throttleMetrics = expvar.NewMap("notify_throttle")
+
+// limiters 按 webhook 地址共享限流器，多个目的地使用同一个机器人时合并计算频率
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*Limiter)
+)
+
+// bucket 是单个时间窗口的令牌桶，tokens 为负数表示已预约的未来令牌
+type bucket struct {
+	capacity float64
+	rate     float64 // 每秒补充的令牌数
+	tokens   float64
+	last     time.Time
+}
+
+// Limiter 是由多个时间窗口的令牌桶组成的限流器，每次请求需要每个令牌桶各取一个令牌
+type Limiter struct {
+	mu      sync.Mutex
+	buckets []*bucket
+}
+
+// NewLimiter 创建限流器，每个限制对应一个令牌桶，初始时令牌是满的
+func NewLimiter(limits []config.RateLimit) *Limiter {
+	now := time.Now()
+	limiter := &Limiter{}
+	for _, limit := range limits {
+		if limit.Requests <= 0 || limit.Per <= 0 {
+			continue
+		}
+		limiter.buckets = append(limiter.buckets, &bucket{
+			capacity: float64(limit.Requests),
+			rate:     float64(limit.Requests) / limit.Per.Seconds(),
+			tokens:   float64(limit.Requests),
+			last:     now,
+		})
+	}
+	return limiter
+}
+
+// reserve 从每个令牌桶中预约一个令牌，返回需要等待的时间
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	for _, b := range l.buckets {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+		b.tokens--
+		if b.tokens < 0 {
+			if d := time.Duration(-b.tokens / b.rate * float64(time.Second)); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait
+}
+
+// tryReserve 在每个令牌桶都有令牌时各取一个令牌并返回 0；否则不取令牌，返回需要等待的时间
+func (l *Limiter) tryReserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	for _, b := range l.buckets {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+		if b.tokens < 1 {
+			if d := time.Duration((1 - b.tokens) / b.rate * float64(time.Second)); d > wait {
+				wait = d
+			}
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range l.buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// cancel 归还预约的令牌
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, b := range l.buckets {
+		b.tokens++
+	}
+}
+
+// Wait 等待直到可以发送下一个请求，返回等待的时间；ctx 取消时归还令牌并返回错误
+func (l *Limiter) Wait(ctx context.Context) (time.Duration, error) {
+	wait := l.reserve(time.Now())
+	if wait <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return wait, nil
+	case <-ctx.Done():
+		l.cancel()
+		return 0, ctx.Err()
+	}
+}
+
+// limiterFor 返回 key 对应的共享限流器，不存在时按 limits 创建
+func limiterFor(key string, limits []config.RateLimit) *Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	if limiter, ok := limiters[key]; ok {
+		return limiter
+	}
+	limiter := NewLimiter(limits)
+	limiters[key] = limiter
+	return limiter
+}
+
+// ThrottledError 表示推送目的地已达到频率限制，消息没有发送，Wait 之后才能再次发送
+type ThrottledError struct {
+	Destination string
+	Wait        time.Duration
+}
+
+// Error 返回错误描述
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("推送到 %s 触发频率限制, %s 后才能发送", e.Destination, e.Wait.Round(time.Millisecond))
+}
+
+// rateLimited 在推送前按频率限制取令牌，没有令牌时不发送并返回 *ThrottledError，由调用方稍后重新推送
+type rateLimited struct {
+	name    string
+	next    Notifier
+	limiter *Limiter
+}
+
+// multiRequester 是一次推送可能发出多个请求的 Notifier，例如飞书卡片被拒绝后改发文本消息；
+// 限流器通过 setRequestWait 注入等待函数，Notifier 在第一个之后的每个请求前调用它再取一个令牌
+type multiRequester interface {
+	setRequestWait(wait func(ctx context.Context) error)
+}
+
+// withRateLimit 为 Notifier 加上频率限制，目的地配置了 rate_limits 时覆盖渠道默认值
+func withRateLimit(cfg config.DestinationConfig, notifier Notifier) Notifier {
+	limits := cfg.RateLimits
+	if len(limits) == 0 {
+		limits = defaultRateLimits[cfg.Type]
+	}
+	if len(limits) == 0 {
+		return notifier
+	}
+
+	key := cfg.Type + "|" + cfg.WebhookURL + "|" + cfg.BotToken
+	limited := &rateLimited{name: cfg.Name, next: notifier, limiter: limiterFor(key, limits)}
+	if m, ok := notifier.(multiRequester); ok {
+		m.setRequestWait(limited.wait)
+	}
+	return limited
+}
+
+// Send 取得令牌后推送，不等待令牌
+func (r *rateLimited) Send(ctx context.Context, item Item) error {
+	if wait := r.limiter.tryReserve(time.Now()); wait > 0 {
+		throttleMetrics.Add(r.name+".throttled", 1)
+		return &ThrottledError{Destination: r.name, Wait: wait}
+	}
+	if err := r.next.Send(ctx, item); err != nil {
+		return err
+	}
+	throttleMetrics.Add(r.name+".sent", 1)
+	return nil
+}
+
+// wait 等待取得一个令牌，用于同一次推送中的后续请求，并记录限流指标
+func (r *rateLimited) wait(ctx context.Context) error {
+	wait, err := r.limiter.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		throttleMetrics.Add(r.name+".throttled", 1)
+		throttleMetrics.Add(r.name+".wait_ms", wait.Milliseconds())
+		if wait >= time.Second {
+			log.Printf("推送到 %s 触发频率限制, 等待 %s\n", r.name, wait.Round(time.Millisecond))
+		}
+	}
+	return nil
+}
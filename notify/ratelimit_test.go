@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"code/config"
+)
+
+func TestLimiterReserveMultipleBuckets(t *testing.T) {
+	// 每 100ms 2 次且每秒 3 次
+	limiter := NewLimiter([]config.RateLimit{{Requests: 2, Per: 100 * time.Millisecond}, {Requests: 3, Per: time.Second}})
+	start := time.Now()
+
+	tests := []struct {
+		name string
+		at   time.Duration // 相对 start 的请求时间
+		want time.Duration
+	}{
+		{"第 1 次", 0, 0},
+		{"第 2 次", 0, 0},
+		{"短窗口用完时按短窗口等待", 0, 50 * time.Millisecond},
+		{"长窗口用完时按长窗口等待", 200 * time.Millisecond, 133 * time.Millisecond},
+		{"已预约的令牌累积等待", 200 * time.Millisecond, 466 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := limiter.reserve(start.Add(tt.at))
+		if got < tt.want-time.Millisecond || got > tt.want+time.Millisecond {
+			t.Errorf("%s: reserve = %s, 期望 %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterTryReserve(t *testing.T) {
+	limiter := NewLimiter([]config.RateLimit{{Requests: 1, Per: time.Second}, {Requests: 2, Per: time.Minute}})
+	start := time.Now()
+
+	if wait := limiter.tryReserve(start); wait != 0 {
+		t.Fatalf("第 1 次 tryReserve = %s, 期望立即取得令牌", wait)
+	}
+	// 没有令牌时不取令牌，多次尝试不会累积等待时间
+	for i := 0; i < 3; i++ {
+		if wait := limiter.tryReserve(start); wait != time.Second {
+			t.Errorf("tryReserve = %s, 期望 1s", wait)
+		}
+	}
+	if wait := limiter.tryReserve(start.Add(time.Second)); wait != 0 {
+		t.Fatalf("1s 后 tryReserve = %s, 期望取得令牌", wait)
+	}
+	// 每分钟 2 次的令牌桶已用完，按该桶等待
+	wait := limiter.tryReserve(start.Add(2 * time.Second))
+	if want := 28 * time.Second; wait < want-time.Millisecond || wait > want+time.Millisecond {
+		t.Errorf("tryReserve = %s, 期望 %s", wait, want)
+	}
+}
+
+func TestLimiterWaitReturnsTokenOnCancel(t *testing.T) {
+	limiter := NewLimiter([]config.RateLimit{{Requests: 1, Per: time.Hour}})
+	if wait, err := limiter.Wait(context.Background()); wait != 0 || err != nil {
+		t.Fatalf("第 1 次 Wait = %s, %v", wait, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, 期望 ctx 超时错误", err)
+	}
+
+	// 取消的等待归还了令牌，下一个请求只需等待一个令牌的时间
+	if wait := limiter.tryReserve(time.Now()); wait > time.Hour {
+		t.Errorf("tryReserve = %s, 取消等待后应归还令牌", wait)
+	}
+}
+
+func TestRateLimitedSend(t *testing.T) {
+	next := &countingNotifier{}
+	limited := &rateLimited{
+		name:    "test-rate-limited",
+		next:    next,
+		limiter: NewLimiter([]config.RateLimit{{Requests: 1, Per: time.Hour}}),
+	}
+
+	if err := limited.Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	err := limited.Send(context.Background(), testItem)
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("Send = %v, 期望 *ThrottledError", err)
+	}
+	if throttled.Destination != "test-rate-limited" || throttled.Wait <= 0 {
+		t.Errorf("ThrottledError = %+v", throttled)
+	}
+	if next.calls != 1 {
+		t.Errorf("推送 %d 次, 达到频率限制时不应推送", next.calls)
+	}
+	if got := throttleMetrics.Get("test-rate-limited.sent").(*expvar.Int).Value(); got != 1 {
+		t.Errorf("sent = %d, 期望 1", got)
+	}
+	if got := throttleMetrics.Get("test-rate-limited.throttled").(*expvar.Int).Value(); got != 1 {
+		t.Errorf("throttled = %d, 期望 1", got)
+	}
+}
+
+func TestRateLimitedSentCountsOnlySuccess(t *testing.T) {
+	limited := &rateLimited{
+		name:    "test-rate-limited-failure",
+		next:    &countingNotifier{err: errors.New("connection refused")},
+		limiter: NewLimiter([]config.RateLimit{{Requests: 10, Per: time.Second}}),
+	}
+	if err := limited.Send(context.Background(), testItem); err == nil {
+		t.Fatal("推送失败时应返回错误")
+	}
+	if got := throttleMetrics.Get("test-rate-limited-failure.sent"); got != nil {
+		t.Errorf("sent = %v, 推送失败不应计数", got)
+	}
+}
+
+// countingNotifier 记录推送次数，返回预设的错误
+type countingNotifier struct {
+	calls int
+	err   error
+}
+
+func (c *countingNotifier) Send(ctx context.Context, item Item) error {
+	c.calls++
+	return c.err
+}
@@ -0,0 +1,261 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordedRequest 是测试服务器收到的一次请求
+type recordedRequest struct {
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// newRecordingServer 记录收到的请求，并以 status（为 0 时为 200）和 response 响应
+func newRecordingServer(t *testing.T, status int, response string) (*httptest.Server, *[]recordedRequest) {
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{path: r.URL.Path, query: r.URL.Query(), header: r.Header, body: body})
+		if status != 0 {
+			w.WriteHeader(status)
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// decodeBody 将请求体解码为 map
+func decodeBody(t *testing.T, body []byte) map[string]interface{} {
+	t.Helper()
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("请求体 %s 无法解析: %v", body, err)
+	}
+	return payload
+}
+
+func TestDingTalkSignedURL(t *testing.T) {
+	const secret = "SEC-test"
+	now := time.UnixMilli(1714550400123)
+
+	d, err := NewDingTalk("https://oapi.dingtalk.com/robot/send?access_token=abc", secret, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewDingTalk: %v", err)
+	}
+	signed, err := d.signedURL(now)
+	if err != nil {
+		t.Fatalf("signedURL: %v", err)
+	}
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("签名后的地址 %q 无效: %v", signed, err)
+	}
+	query := parsed.Query()
+
+	if got := query.Get("access_token"); got != "abc" {
+		t.Errorf("access_token = %q, 应保留原有参数", got)
+	}
+	if got := query.Get("timestamp"); got != "1714550400123" {
+		t.Errorf("timestamp = %q, 期望毫秒时间戳 1714550400123", got)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("1714550400123\n" + secret))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); query.Get("sign") != want {
+		t.Errorf("sign = %q, 期望 %q", query.Get("sign"), want)
+	}
+
+	unsigned, _ := NewDingTalk("https://oapi.dingtalk.com/robot/send?access_token=abc", "", http.DefaultClient)
+	if got, _ := unsigned.signedURL(now); got != "https://oapi.dingtalk.com/robot/send?access_token=abc" {
+		t.Errorf("未配置 secret 时地址 = %q, 不应加签", got)
+	}
+}
+
+func TestDingTalkSend(t *testing.T) {
+	server, requests := newRecordingServer(t, 0, `{"errcode":0,"errmsg":"ok"}`)
+	d, _ := NewDingTalk(server.URL+"/robot/send?access_token=abc", "SEC-test", server.Client())
+	if err := d.Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := (*requests)[0]
+	timestamp, err := strconv.ParseInt(req.query.Get("timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp %q 无效: %v", req.query.Get("timestamp"), err)
+	}
+	if d := time.Since(time.UnixMilli(timestamp)); d < -time.Minute || d > time.Minute {
+		t.Errorf("timestamp 与当前时间相差 %s", d)
+	}
+	if req.query.Get("sign") == "" {
+		t.Error("配置了 secret 时应带 sign 参数")
+	}
+	payload := decodeBody(t, req.body)
+	if payload["msgtype"] != "text" {
+		t.Errorf("msgtype = %v, 期望 text", payload["msgtype"])
+	}
+	if content, _ := payload["text"].(map[string]interface{})["content"].(string); !strings.Contains(content, testItem.Title) {
+		t.Errorf("消息内容 %q 缺少标题", content)
+	}
+}
+
+func TestErrCodeSinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string // 为空表示推送成功
+	}{
+		{"成功", `{"errcode":0,"errmsg":"ok"}`, ""},
+		{"errcode 非 0", `{"errcode":310000,"errmsg":"sign not match"}`, "errcode=310000 errmsg=sign not match"},
+		{"响应无法解析", `not json`, "响应解析失败"},
+	}
+	sinks := []struct {
+		name string
+		new  func(url string, client *http.Client) Notifier
+	}{
+		{"钉钉", func(url string, client *http.Client) Notifier { d, _ := NewDingTalk(url, "", client); return d }},
+		{"企业微信", func(url string, client *http.Client) Notifier { w, _ := NewWeCom(url, client); return w }},
+	}
+	for _, sink := range sinks {
+		for _, tt := range tests {
+			t.Run(sink.name+"/"+tt.name, func(t *testing.T) {
+				server, requests := newRecordingServer(t, 0, tt.response)
+				err := sink.new(server.URL, server.Client()).Send(context.Background(), testItem)
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Errorf("Send = %v, 期望成功", err)
+					}
+				} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), sink.name) {
+					t.Errorf("Send = %v, 期望包含 %q 和渠道名", err, tt.wantErr)
+				}
+				if payload := decodeBody(t, (*requests)[0].body); payload["msgtype"] != "text" {
+					t.Errorf("msgtype = %v, 期望 text", payload["msgtype"])
+				}
+			})
+		}
+	}
+}
+
+func TestTelegramSend(t *testing.T) {
+	const token = "123456:SECRET-TOKEN"
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantErr  string
+	}{
+		{"成功", 0, `{"ok":true,"result":{}}`, ""},
+		{"ok 为 false", 0, `{"ok":false,"description":"Bad Request: chat not found"}`, "chat not found"},
+		{"HTTP 错误", http.StatusUnauthorized, `{"ok":false,"description":"Unauthorized"}`, "401"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newRecordingServer(t, tt.status, tt.response)
+			telegram, err := NewTelegram(server.URL+"/", token, "-1001", server.Client())
+			if err != nil {
+				t.Fatalf("NewTelegram: %v", err)
+			}
+			err = telegram.Send(context.Background(), testItem)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Send = %v, 期望成功", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Send = %v, 期望包含 %q", err, tt.wantErr)
+			}
+
+			req := (*requests)[0]
+			if req.path != "/bot"+token+"/sendMessage" {
+				t.Errorf("请求路径 = %q", req.path)
+			}
+			if payload := decodeBody(t, req.body); payload["chat_id"] != "-1001" {
+				t.Errorf("chat_id = %v, 期望 -1001", payload["chat_id"])
+			}
+		})
+	}
+}
+
+func TestTelegramRedactsToken(t *testing.T) {
+	const token = "123456:SECRET-TOKEN"
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close() // 连接失败时错误信息中包含请求地址
+
+	telegram, _ := NewTelegram(server.URL, token, "-1001", &http.Client{Timeout: time.Second})
+	err := telegram.Send(context.Background(), testItem)
+	if err == nil {
+		t.Fatal("连接失败时应返回错误")
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Errorf("错误信息 %q 不应包含 bot token", err)
+	}
+	if !strings.Contains(err.Error(), "/bot***/sendMessage") {
+		t.Errorf("错误信息 %q 应保留脱敏后的地址", err)
+	}
+}
+
+func TestSlackSend(t *testing.T) {
+	server, requests := newRecordingServer(t, 0, "ok")
+	slack, _ := NewSlack(server.URL, server.Client())
+	if err := slack.Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := (*requests)[0]
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	payload := decodeBody(t, req.body)
+	if payload["text"] != FormatText(testItem) {
+		t.Errorf("text = %v, 期望 %q", payload["text"], FormatText(testItem))
+	}
+
+	failing, _ := newRecordingServer(t, http.StatusNotFound, "no_service")
+	slack, _ = NewSlack(failing.URL, failing.Client())
+	if err := slack.Send(context.Background(), testItem); err == nil || !strings.Contains(err.Error(), "no_service") {
+		t.Errorf("Send = %v, 期望包含响应内容", err)
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusNoContent, "")
+	headers := map[string]string{"Authorization": "Bearer abc", "X-Source": "news"}
+	webhook, _ := NewWebhook(server.URL+"/hook", headers, server.Client())
+	if err := webhook.Send(context.Background(), testItem); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := (*requests)[0]
+	for key, value := range headers {
+		if got := req.header.Get(key); got != value {
+			t.Errorf("请求头 %s = %q, 期望 %q", key, got, value)
+		}
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var item Item
+	if err := json.Unmarshal(req.body, &item); err != nil {
+		t.Fatalf("请求体无法解析: %v", err)
+	}
+	if item.Site != testItem.Site || item.Title != testItem.Title || item.Link != testItem.Link {
+		t.Errorf("请求体 = %+v, 期望 %+v", item, testItem)
+	}
+
+	failing, _ := newRecordingServer(t, http.StatusInternalServerError, "boom")
+	webhook, _ = NewWebhook(failing.URL, nil, failing.Client())
+	if err := webhook.Send(context.Background(), testItem); err == nil {
+		t.Error("HTTP 500 时应返回错误")
+	}
+}
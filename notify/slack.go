@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Slack 是 Slack incoming webhook 的 Notifier 实现
+type Slack struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlack 创建 Slack incoming webhook 推送
+func NewSlack(webhookURL string, client *http.Client) (*Slack, error) {
+	if webhookURL == "" {
+		return nil, fmt.Errorf("Slack 推送缺少 webhook_url")
+	}
+	return &Slack{webhookURL: webhookURL, client: client}, nil
+}
+
+// Send 以文本消息推送到 Slack，incoming webhook 成功时返回 200 和 "ok"
+func (s *Slack) Send(ctx context.Context, item Item) error {
+	payload := map[string]interface{}{
+		"text": FormatText(item),
+	}
+
+	_, err := postJSON(ctx, s.client, s.webhookURL, payload, nil)
+	return err
+}
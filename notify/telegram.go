@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// defaultTelegramAPIURL 是 Telegram Bot API 的默认地址
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// Telegram 是 Telegram Bot API 的 Notifier 实现
+type Telegram struct {
+	apiURL   string
+	botToken string
+	chatID   string
+	client   *http.Client
+}
+
+// NewTelegram 创建 Telegram 机器人推送，apiURL 为空时使用官方地址
+func NewTelegram(apiURL, botToken, chatID string, client *http.Client) (*Telegram, error) {
+	if botToken == "" || chatID == "" {
+		return nil, fmt.Errorf("Telegram 推送缺少 bot_token 或 chat_id")
+	}
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+	return &Telegram{
+		apiURL:   strings.TrimRight(apiURL, "/"),
+		botToken: botToken,
+		chatID:   chatID,
+		client:   client,
+	}, nil
+}
+
+// Send 调用 sendMessage 推送文本消息
+func (t *Telegram) Send(ctx context.Context, item Item) error {
+	payload := map[string]interface{}{
+		"chat_id": t.chatID,
+		"text":    FormatText(item),
+	}
+
+	body, err := postJSON(ctx, t.client, t.apiURL+"/bot"+t.botToken+"/sendMessage", payload, nil)
+	if err != nil {
+		// 错误信息中的 URL 包含 bot token，不能写入日志
+		return fmt.Errorf("Telegram 推送失败: %s", strings.ReplaceAll(err.Error(), t.botToken, "***"))
+	}
+
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("Telegram 响应解析失败: %v", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("Telegram 推送失败: %s", resp.Description)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Webhook 是通用 JSON webhook 的 Notifier 实现，请求体为 Item 的 JSON
+type Webhook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhook 创建通用 JSON webhook 推送
+func NewWebhook(url string, headers map[string]string, client *http.Client) (*Webhook, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook 推送缺少 webhook_url")
+	}
+	return &Webhook{url: url, headers: headers, client: client}, nil
+}
+
+// Send 将条目以 JSON 格式 POST 到 webhook 地址
+func (w *Webhook) Send(ctx context.Context, item Item) error {
+	_, err := postJSON(ctx, w.client, w.url, item, w.headers)
+	return err
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// WeCom 是企业微信群机器人的 Notifier 实现
+type WeCom struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewWeCom 创建企业微信群机器人推送
+func NewWeCom(webhookURL string, client *http.Client) (*WeCom, error) {
+	if webhookURL == "" {
+		return nil, fmt.Errorf("企业微信推送缺少 webhook_url")
+	}
+	return &WeCom{webhookURL: webhookURL, client: client}, nil
+}
+
+// Send 以文本消息推送到企业微信
+func (w *WeCom) Send(ctx context.Context, item Item) error {
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": FormatText(item)},
+	}
+
+	body, err := postJSON(ctx, w.client, w.webhookURL, payload, nil)
+	if err != nil {
+		return err
+	}
+	return checkErrCode("企业微信", body)
+}
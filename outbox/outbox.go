@@ -0,0 +1,327 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"code/config"
+	"code/db"
+	"code/notify"
+)
+
+// Queue 是推送消息在数据库中的队列名称
+const Queue = "deliveries"
+
+// batchSize 是每次从队列中读取的消息数
+const batchSize = 100
+
+// Delivery 是发往单个推送目的地的一条消息
+type Delivery struct {
+	ID          string      `json:"id"`
+	Destination string      `json:"destination"`
+	Item        notify.Item `json:"item"`
+	Attempts    int         `json:"attempts"`             // 已推送次数
+	LastError   string      `json:"last_error,omitempty"` // 最近一次推送失败的原因
+	CreatedAt   time.Time   `json:"created_at"`
+	NextAttempt time.Time   `json:"next_attempt"` // 推送失败后下次重试的时间
+	FailedAt    time.Time   `json:"failed_at"`    // 移入死信列表的时间
+
+	// Raw 是无法解析的死信的原始内容，只由 DeadLetters 设置，这类死信不能重放
+	Raw string `json:"-"`
+}
+
+// orderKey 返回消息的顺序键，同一目的地、同一站点的消息按入队顺序依次推送；
+// 站点的故障告警和恢复通知单独排序，不会被等待重试的新闻消息阻塞
+func (d *Delivery) orderKey() string {
+	switch d.Item.Category {
+	case notify.CategoryAlert, notify.CategoryRecovered:
+		return d.Destination + "|health|" + d.Item.Site
+	default:
+		return d.Destination + "|" + d.Item.Site
+	}
+}
+
+// Outbox 是持久化的推送队列：消息先写入数据库，再由 Dispatch 推送，失败时按指数退避重试，
+// 超过最大次数或遇到不可重试的错误时移入死信列表。失败的消息保留在队列中的原有位置，
+// 在它推送成功或移入死信列表之前，同一目的地、同一站点的后续消息不会推送，保证各站点消息的顺序
+type Outbox struct {
+	client    db.DatabaseClient
+	notifiers map[string]notify.Notifier
+	cfg       config.DeliveryConfig
+
+	mu   sync.Mutex    // 保证同一时间只有一个 Dispatch 在推送，避免重复发送
+	wake chan struct{} // Trigger 通知 Run 立即推送，缓冲为 1，多次通知会合并
+
+	enqueueMu sync.Mutex
+	lastAt    time.Time // 上一条消息的入队时间，保证入队时间严格递增，同一站点的消息按入队顺序推送
+}
+
+// NewOutbox 创建推送队列
+func NewOutbox(client db.DatabaseClient, notifiers map[string]notify.Notifier, cfg config.DeliveryConfig) *Outbox {
+	return &Outbox{client: client, notifiers: notifiers, cfg: cfg, wake: make(chan struct{}, 1)}
+}
+
+// Enqueue 为每个推送目的地创建一条消息并写入队列
+func (o *Outbox) Enqueue(ctx context.Context, item notify.Item, destinations []string) error {
+	now := o.enqueueTime()
+	fingerprint := db.Fingerprint(item.Link, item.OriginalTitle)
+	for _, name := range destinations {
+		delivery := &Delivery{
+			ID:          name + "|" + fingerprint,
+			Destination: name,
+			Item:        item,
+			CreatedAt:   now,
+		}
+		if err := o.save(ctx, delivery, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueTime 返回严格递增的入队时间，队列按毫秒排序，同一毫秒内入队的消息顺序后移
+func (o *Outbox) enqueueTime() time.Time {
+	o.enqueueMu.Lock()
+	defer o.enqueueMu.Unlock()
+
+	now := time.Now().Truncate(time.Millisecond)
+	if !now.After(o.lastAt) {
+		now = o.lastAt.Add(time.Millisecond)
+	}
+	o.lastAt = now
+	return now
+}
+
+// Trigger 通知 Run 尽快推送到期的消息，不等待推送完成；Run 正在推送时会在本次推送结束后再推送一次
+func (o *Outbox) Trigger() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run 每隔 PollInterval 或收到 Trigger 通知时推送到期的消息，有目的地达到频率限制时在令牌恢复后再推送一次；ctx 取消后返回
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var throttled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		case <-throttled:
+		}
+		throttled = nil
+		if wait := o.dispatch(ctx); wait > 0 {
+			throttled = time.After(wait)
+		}
+	}
+}
+
+// dispatchPass 是一次 Dispatch 的推送状态
+type dispatchPass struct {
+	now       time.Time
+	held      map[string]bool // 有更早的消息在等待重试的顺序键
+	throttled map[string]bool // 已达到频率限制的推送目的地，本次不再推送
+	wait      time.Duration   // 达到频率限制的目的地中最早恢复令牌的等待时间
+}
+
+// Dispatch 按入队顺序推送队列中到期的消息，每次从队列读取 batchSize 条，直到没有到期的消息；
+// 达到频率限制的目的地的消息留到下次推送，不阻塞其他目的地；ctx 取消后不再推送新的消息，未推送的消息留在队列中
+func (o *Outbox) Dispatch(ctx context.Context) {
+	o.dispatch(ctx)
+}
+
+// dispatch 实现 Dispatch，返回达到频率限制的目的地恢复令牌的最短等待时间，没有目的地达到频率限制时返回 0
+func (o *Outbox) dispatch(ctx context.Context) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pass := &dispatchPass{
+		now:       time.Now(),
+		held:      make(map[string]bool),
+		throttled: make(map[string]bool),
+	}
+	var offset int64 // 已读取但仍留在队列中的消息数
+	for {
+		messages, err := o.client.DueMessages(ctx, Queue, pass.now, offset, batchSize)
+		if err != nil {
+			log.Printf("读取推送队列失败: %v\n", err)
+			return pass.wait
+		}
+		for _, message := range messages {
+			if ctx.Err() != nil {
+				return pass.wait
+			}
+			if !o.deliver(ctx, message, pass) {
+				offset++
+			}
+		}
+		if len(messages) < batchSize {
+			return pass.wait
+		}
+	}
+}
+
+// deliver 推送一条消息，成功时从队列删除，失败时安排重试或移入死信列表；返回消息是否已离开队列
+// 同一顺序键下有更早的消息在等待重试，或目的地在本次推送中已达到频率限制时，消息保持原样留在队列中
+func (o *Outbox) deliver(ctx context.Context, message db.QueuedMessage, pass *dispatchPass) bool {
+	var delivery Delivery
+	if err := json.Unmarshal([]byte(message.Payload), &delivery); err != nil {
+		log.Printf("推送队列中的消息 %s 无法解析, 移入死信列表: %v\n", message.ID, err)
+		if err := o.client.MoveToDeadLetter(ctx, Queue, message.ID, message.Payload); err != nil {
+			log.Printf("%v\n", err)
+			return false
+		}
+		return true
+	}
+
+	key := delivery.orderKey()
+	if pass.held[key] || pass.throttled[delivery.Destination] {
+		return false
+	}
+	if delivery.NextAttempt.After(pass.now) {
+		pass.held[key] = true
+		return false
+	}
+
+	notifier, ok := o.notifiers[delivery.Destination]
+	if !ok {
+		return o.deadLetter(ctx, &delivery, fmt.Errorf("推送目的地 %s 不存在", delivery.Destination))
+	}
+
+	err := notifier.Send(ctx, delivery.Item)
+	var throttled *notify.ThrottledError
+	if errors.As(err, &throttled) {
+		// 消息没有发送，不计入推送次数
+		pass.throttled[delivery.Destination] = true
+		if pass.wait == 0 || throttled.Wait < pass.wait {
+			pass.wait = throttled.Wait
+		}
+		return false
+	}
+	delivery.Attempts++
+	if err == nil {
+		// 推送已成功，即使正在退出也要从队列中删除，避免下次启动重复推送
+		if err := o.client.Dequeue(context.WithoutCancel(ctx), Queue, delivery.ID); err != nil {
+			log.Printf("%v\n", err)
+			return false
+		}
+		log.Printf("已推送到 %s: %s\n", delivery.Destination, delivery.Item.Link)
+		return true
+	}
+
+	if ctx.Err() != nil {
+		// 推送因退出被中止，消息保持原样留在队列中，下次启动时重新推送
+		return false
+	}
+	if !notify.IsRetryable(err) || delivery.Attempts >= o.cfg.MaxAttempts {
+		return o.deadLetter(ctx, &delivery, err)
+	}
+
+	// 消息保留在队列中的原有位置，到重试时间之前同一顺序键的后续消息都不会推送
+	pass.held[key] = true
+	delivery.LastError = err.Error()
+	delay := o.backoff(delivery.Attempts)
+	delivery.NextAttempt = time.Now().Add(delay)
+	log.Printf("推送到 %s 失败 (第 %d 次), %s 后重试: %v\n", delivery.Destination, delivery.Attempts, delay.Round(time.Second), err)
+	if err := o.save(ctx, &delivery, message.At); err != nil {
+		log.Printf("%v\n", err)
+	}
+	return false
+}
+
+// deadLetter 将消息移入死信列表，返回消息是否已离开队列
+func (o *Outbox) deadLetter(ctx context.Context, delivery *Delivery, cause error) bool {
+	delivery.LastError = cause.Error()
+	delivery.FailedAt = time.Now()
+	log.Printf("推送到 %s 失败 (共 %d 次), 移入死信列表: %s: %v\n", delivery.Destination, delivery.Attempts, delivery.Item.Link, cause)
+
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		log.Printf("消息序列化失败: %v\n", err)
+		return false
+	}
+	if err := o.client.MoveToDeadLetter(ctx, Queue, delivery.ID, string(payload)); err != nil {
+		log.Printf("%v\n", err)
+		return false
+	}
+	return true
+}
+
+// backoff 返回第 attempts 次失败后的重试等待时间：以 InitialBackoff 为基数指数增长、不超过 MaxBackoff，
+// 并在 [delay/2, delay) 范围内随机抖动，避免大量消息同时重试
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.cfg.InitialBackoff
+	for i := 1; i < attempts && delay < o.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.cfg.MaxBackoff {
+		delay = o.cfg.MaxBackoff
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// save 将消息写入队列，at 为最早推送时间
+func (o *Outbox) save(ctx context.Context, delivery *Delivery, at time.Time) error {
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("消息序列化失败: %v", err)
+	}
+	return o.client.Enqueue(ctx, Queue, delivery.ID, string(payload), at)
+}
+
+// DeadLetters 返回死信列表中的消息，无法解析的死信只设置 ID 和 Raw
+func DeadLetters(ctx context.Context, client db.DatabaseClient) ([]Delivery, error) {
+	messages, err := client.DeadLetters(ctx, Queue)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]Delivery, 0, len(messages))
+	for _, message := range messages {
+		var delivery Delivery
+		if err := json.Unmarshal([]byte(message.Payload), &delivery); err != nil {
+			delivery = Delivery{LastError: fmt.Sprintf("无法解析: %v", err), Raw: message.Payload}
+		}
+		delivery.ID = message.ID
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// Replay 将死信重新放回推送队列并清零推送次数，由运行中的服务重新推送；无法解析的死信不能重放，保留在死信列表中
+func Replay(ctx context.Context, client db.DatabaseClient, delivery Delivery) error {
+	if delivery.Raw != "" {
+		return fmt.Errorf("死信 %s 无法解析, 不能重放", delivery.ID)
+	}
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.NextAttempt = time.Time{}
+	delivery.FailedAt = time.Time{}
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("消息序列化失败: %v", err)
+	}
+	if err := client.Enqueue(ctx, Queue, delivery.ID, string(payload), time.Now()); err != nil {
+		return err
+	}
+	return client.RemoveDeadLetter(ctx, Queue, delivery.ID)
+}
+
+// Purge 从死信列表中删除消息
+func Purge(ctx context.Context, client db.DatabaseClient, id string) error {
+	return client.RemoveDeadLetter(ctx, Queue, id)
+}
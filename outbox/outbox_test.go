@@ -0,0 +1,313 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code/config"
+	"code/db/dbtest"
+	"code/lark"
+	"code/notify"
+)
+
+// scriptedNotifier 记录推送的标题，并按标题依次返回预设的错误，预设用完后推送成功
+type scriptedNotifier struct {
+	mu     sync.Mutex
+	sent   []string
+	errors map[string][]error
+}
+
+func (s *scriptedNotifier) Send(ctx context.Context, item notify.Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, item.Title)
+	if errs := s.errors[item.Title]; len(errs) > 0 {
+		s.errors[item.Title] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+func (s *scriptedNotifier) titles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.sent)
+}
+
+var errTemporary = errors.New("connection reset")
+
+// newTestOutbox 创建使用内存数据库和单个推送目的地 d 的推送队列，重试等待时间为毫秒级
+func newTestOutbox(errs map[string][]error, maxAttempts int) (*Outbox, *dbtest.Memory, *scriptedNotifier) {
+	client := dbtest.NewMemory()
+	notifier := &scriptedNotifier{errors: errs}
+	cfg := config.DeliveryConfig{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 2 * time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+		PollInterval:   time.Hour,
+	}
+	return NewOutbox(client, map[string]notify.Notifier{"d": notifier}, cfg), client, notifier
+}
+
+func enqueue(t *testing.T, o *Outbox, site, category, title string) {
+	t.Helper()
+	item := notify.Item{Site: site, Category: category, Title: title, OriginalTitle: title, Link: "https://example.com/" + title}
+	if err := o.Enqueue(context.Background(), item, []string{"d"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+}
+
+func TestDispatchKeepsSiteOrderWhileRetrying(t *testing.T) {
+	o, client, notifier := newTestOutbox(map[string][]error{"a1": {errTemporary}}, 8)
+	enqueue(t, o, "A", "tech", "a1")
+	enqueue(t, o, "A", "tech", "a2")
+	enqueue(t, o, "B", "tech", "b1")
+	enqueue(t, o, "A", "tech", "a3")
+	enqueue(t, o, "A", notify.CategoryAlert, "alert")
+	time.Sleep(10 * time.Millisecond) // 同一毫秒内入队的消息入队时间依次后移，等待全部到期
+
+	// a1 失败后同一站点的 a2、a3 等待，其他站点和站点告警不受影响
+	o.Dispatch(context.Background())
+	if got, want := notifier.titles(), []string{"a1", "b1", "alert"}; !slices.Equal(got, want) {
+		t.Fatalf("第一次推送 %v, 期望 %v", got, want)
+	}
+	if queued := len(client.Queue(Queue)); queued != 3 {
+		t.Errorf("队列中剩余 %d 条, 期望 3 条", queued)
+	}
+
+	// 重试时间未到时不推送
+	o.Dispatch(context.Background())
+	if got := len(notifier.titles()); got != 3 {
+		t.Errorf("重试时间未到时推送了 %d 条, 期望不推送", got-3)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	o.Dispatch(context.Background())
+	if got, want := notifier.titles(), []string{"a1", "b1", "alert", "a1", "a2", "a3"}; !slices.Equal(got, want) {
+		t.Errorf("推送顺序 %v, 期望 %v", got, want)
+	}
+	if queued := len(client.Queue(Queue)); queued != 0 {
+		t.Errorf("队列中剩余 %d 条, 期望全部推送", queued)
+	}
+}
+
+func TestDispatchRetryKeepsQueuePosition(t *testing.T) {
+	o, client, _ := newTestOutbox(map[string][]error{"a1": {errTemporary}}, 8)
+	enqueue(t, o, "A", "tech", "a1")
+	before := client.Queue(Queue)[0]
+
+	o.Dispatch(context.Background())
+
+	queued := client.Queue(Queue)
+	if len(queued) != 1 || !queued[0].At.Equal(before.At) {
+		t.Fatalf("失败的消息应保留在原有位置, 队列 = %+v", queued)
+	}
+	deliveries := decodeQueue(t, client)
+	if deliveries[0].Attempts != 1 || deliveries[0].LastError != errTemporary.Error() {
+		t.Errorf("Attempts = %d, LastError = %q", deliveries[0].Attempts, deliveries[0].LastError)
+	}
+	if !deliveries[0].NextAttempt.After(time.Now().Add(-time.Second)) {
+		t.Errorf("NextAttempt = %s, 应在当前时间之后", deliveries[0].NextAttempt)
+	}
+}
+
+func TestDispatchHoldsThrottledDestination(t *testing.T) {
+	throttled := &notify.ThrottledError{Destination: "d", Wait: 5 * time.Millisecond}
+	o, client, notifier := newTestOutbox(map[string][]error{"a1": {throttled}}, 8)
+	enqueue(t, o, "A", "tech", "a1")
+	enqueue(t, o, "B", "tech", "b1")
+	time.Sleep(10 * time.Millisecond)
+
+	// 达到频率限制后本次不再推送该目的地的任何消息，也不计入推送次数
+	if wait := o.dispatch(context.Background()); wait != throttled.Wait {
+		t.Errorf("dispatch = %s, 期望返回令牌恢复的等待时间 %s", wait, throttled.Wait)
+	}
+	if got := notifier.titles(); !slices.Equal(got, []string{"a1"}) {
+		t.Errorf("推送 %v, 达到频率限制后不应继续推送", got)
+	}
+	for _, delivery := range decodeQueue(t, client) {
+		if delivery.Attempts != 0 || !delivery.NextAttempt.IsZero() {
+			t.Errorf("消息 %s = %+v, 达到频率限制不应计入推送次数", delivery.ID, delivery)
+		}
+	}
+
+	if wait := o.dispatch(context.Background()); wait != 0 {
+		t.Errorf("dispatch = %s, 期望 0", wait)
+	}
+	if got, want := notifier.titles(), []string{"a1", "a1", "b1"}; !slices.Equal(got, want) {
+		t.Errorf("推送 %v, 期望 %v", got, want)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	o := &Outbox{cfg: config.DeliveryConfig{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+	tests := []struct {
+		attempts int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{4, 4 * time.Second, 8 * time.Second},
+		{5, 5 * time.Second, 10 * time.Second},
+		{20, 5 * time.Second, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if got := o.backoff(tt.attempts); got < tt.min || got >= tt.max {
+				t.Fatalf("backoff(%d) = %s, 期望在 [%s, %s) 范围内", tt.attempts, got, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestDispatchDeadLetters(t *testing.T) {
+	nonRetryable := &lark.APIError{StatusCode: 200, Code: lark.CodeSignMismatch, Msg: "sign match fail"}
+	tests := []struct {
+		name         string
+		errs         []error
+		passes       int
+		wantAttempts int
+		wantError    string
+	}{
+		{"达到最大推送次数", []error{errTemporary, errTemporary, errTemporary}, 3, 2, errTemporary.Error()},
+		{"不可重试的错误", []error{nonRetryable}, 1, 1, "sign match fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, client, notifier := newTestOutbox(map[string][]error{"a1": tt.errs}, 2)
+			enqueue(t, o, "A", "tech", "a1")
+			for i := 0; i < tt.passes; i++ {
+				o.Dispatch(context.Background())
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if got := len(notifier.titles()); got != tt.wantAttempts {
+				t.Errorf("推送 %d 次, 期望 %d 次", got, tt.wantAttempts)
+			}
+			if queued := len(client.Queue(Queue)); queued != 0 {
+				t.Errorf("队列中剩余 %d 条, 期望移入死信列表", queued)
+			}
+			deliveries, err := DeadLetters(context.Background(), client)
+			if err != nil || len(deliveries) != 1 {
+				t.Fatalf("DeadLetters = %+v, %v", deliveries, err)
+			}
+			delivery := deliveries[0]
+			if delivery.Attempts != tt.wantAttempts || !strings.Contains(delivery.LastError, tt.wantError) || delivery.FailedAt.IsZero() {
+				t.Errorf("死信 = %+v, 期望推送 %d 次且错误包含 %q", delivery, tt.wantAttempts, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestDispatchUnknownDestination(t *testing.T) {
+	o, client, _ := newTestOutbox(nil, 8)
+	item := notify.Item{Site: "A", Title: "a1", OriginalTitle: "a1", Link: "https://example.com/a1"}
+	if err := o.Enqueue(context.Background(), item, []string{"removed"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	o.Dispatch(context.Background())
+
+	deliveries, _ := DeadLetters(context.Background(), client)
+	if len(deliveries) != 1 || !strings.Contains(deliveries[0].LastError, "removed") {
+		t.Errorf("目的地不存在的消息应移入死信列表, 死信 = %+v", deliveries)
+	}
+}
+
+func TestDispatchUnparseableMessage(t *testing.T) {
+	o, client, notifier := newTestOutbox(nil, 8)
+	client.Enqueue(context.Background(), Queue, "broken", "{not json", time.Now().Add(-time.Second))
+	enqueue(t, o, "A", "tech", "a1")
+	time.Sleep(10 * time.Millisecond)
+
+	o.Dispatch(context.Background())
+
+	if got := notifier.titles(); !slices.Equal(got, []string{"a1"}) {
+		t.Errorf("推送 %v, 无法解析的消息不应阻塞后续消息", got)
+	}
+	deliveries, err := DeadLetters(context.Background(), client)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("DeadLetters = %+v, %v", deliveries, err)
+	}
+	if deliveries[0].ID != "broken" || deliveries[0].Raw != "{not json" || !strings.HasPrefix(deliveries[0].LastError, "无法解析") {
+		t.Errorf("死信 = %+v, 期望保留原始内容", deliveries[0])
+	}
+}
+
+func TestReplayAndPurge(t *testing.T) {
+	ctx := context.Background()
+	o, client, notifier := newTestOutbox(map[string][]error{"a1": {errTemporary}}, 1)
+	enqueue(t, o, "A", "tech", "a1")
+	o.Dispatch(ctx)
+	client.MoveToDeadLetter(ctx, Queue, "broken", "{not json")
+
+	deliveries, _ := DeadLetters(ctx, client)
+	if len(deliveries) != 2 {
+		t.Fatalf("死信 %d 条, 期望 2 条", len(deliveries))
+	}
+	broken, failed := deliveries[0], deliveries[1]
+
+	if err := Replay(ctx, client, broken); err == nil {
+		t.Error("无法解析的死信不应重放")
+	}
+	if err := Replay(ctx, client, failed); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	queued := decodeQueue(t, client)
+	if len(queued) != 1 || queued[0].Attempts != 0 || queued[0].LastError != "" || !queued[0].FailedAt.IsZero() {
+		t.Errorf("重放的消息 = %+v, 应清零推送次数和错误", queued)
+	}
+	o.Dispatch(ctx)
+	if got := notifier.titles(); !slices.Equal(got, []string{"a1", "a1"}) {
+		t.Errorf("推送 %v, 重放后应重新推送", got)
+	}
+
+	deliveries, _ = DeadLetters(ctx, client)
+	if len(deliveries) != 1 || deliveries[0].ID != "broken" {
+		t.Fatalf("死信 = %+v, 期望只剩无法解析的死信", deliveries)
+	}
+	if err := Purge(ctx, client, "broken"); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if deliveries, _ = DeadLetters(ctx, client); len(deliveries) != 0 {
+		t.Errorf("Purge 后仍有死信 %+v", deliveries)
+	}
+}
+
+func TestOrderKey(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"tech", "d|A"},
+		{"", "d|A"},
+		{notify.CategoryAlert, "d|health|A"},
+		{notify.CategoryRecovered, "d|health|A"},
+	}
+	for _, tt := range tests {
+		delivery := Delivery{Destination: "d", Item: notify.Item{Site: "A", Category: tt.category}}
+		if got := delivery.orderKey(); got != tt.want {
+			t.Errorf("orderKey(%q) = %q, 期望 %q", tt.category, got, tt.want)
+		}
+	}
+}
+
+// decodeQueue 解析队列中的全部消息
+func decodeQueue(t *testing.T, client *dbtest.Memory) []Delivery {
+	t.Helper()
+	var deliveries []Delivery
+	for _, message := range client.Queue(Queue) {
+		var delivery Delivery
+		if err := json.Unmarshal([]byte(message.Payload), &delivery); err != nil {
+			t.Fatalf("队列消息 %s 无法解析: %v", message.ID, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
@@ -0,0 +1,182 @@
+package parse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"code/config"
+)
+
+// builtinDateFormats 在站点配置的格式都不匹配时依次尝试，覆盖 ISO 8601、中文日期和订阅源中常见的格式
+var builtinDateFormats = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"2006.01.02",
+	"2006年1月2日 15:04",
+	"2006年1月2日15:04",
+	"2006年1月2日",
+	"2006年1月",
+	"1月2日 15:04",
+	"1月2日",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"January 2, 2006",
+	"Jan 2, 2006 3:04 PM MST",
+	"Jan 2, 2006",
+	"2 January 2006",
+}
+
+var (
+	// 英文相对时间，例如 "3 hours ago"、"an hour ago"
+	englishAgoPattern = regexp.MustCompile(`(?i)^(\d+|a|an|one)\s+(second|sec|minute|min|hour|hr|day|week|month|year)s?\s+ago$`)
+	// 中文相对时间，例如 "2小时前"、"3 天前"
+	chineseAgoPattern = regexp.MustCompile(`^(\d+|半|一|两|几)\s*(秒|分钟|分|小时|个小时|天|日|周|星期|个月|月|年)前$`)
+	// 中文相对日期，可带时刻，例如 "昨天"、"今天 10:30"
+	chineseDayPattern = regexp.MustCompile(`^(今天|昨天|前天)\s*(\d{1,2}:\d{2})?$`)
+	// 英文相对日期，可带时刻，例如 "yesterday"、"today 10:30"
+	englishDayPattern = regexp.MustCompile(`(?i)^(today|yesterday)\s*(?:at\s*)?(\d{1,2}:\d{2})?$`)
+)
+
+// parseDate 按站点时区解析日期字符串：依次尝试站点配置的所有格式、内置格式和相对日期，全部失败时返回逐个格式的错误汇总
+func parseDate(dateStr string, siteConfig config.SiteConfig) (time.Time, error) {
+	dateStr = normalizeDateString(dateStr)
+	if dateStr == "" {
+		return time.Time{}, fmt.Errorf("日期为空")
+	}
+
+	return matchDate(dateStr, siteConfig.DateFormats, time.Now().In(siteConfig.Location()))
+}
+
+// matchDate 按顺序尝试配置格式、内置格式和相对日期，不带时区的日期按 now 所在的时区解析
+func matchDate(dateStr string, formats []string, now time.Time) (time.Time, error) {
+	var errs []string
+	for _, format := range formats {
+		date, err := time.ParseInLocation(format, dateStr, now.Location())
+		if err == nil {
+			return fillMissingYear(date, now), nil
+		}
+		errs = append(errs, fmt.Sprintf("格式 %q: %v", format, err))
+	}
+
+	for _, format := range builtinDateFormats {
+		if date, err := time.ParseInLocation(format, dateStr, now.Location()); err == nil {
+			return fillMissingYear(date, now), nil
+		}
+	}
+
+	if date, ok := parseRelativeDate(dateStr, now); ok {
+		return date, nil
+	}
+
+	errs = append(errs, "内置的 ISO 8601、中文日期、订阅源日期格式和相对日期均不匹配")
+	return time.Time{}, fmt.Errorf("日期解析错误 %q: %s", dateStr, strings.Join(errs, "; "))
+}
+
+// normalizeDateString 去除日期中多余的空白和不换行空格
+func normalizeDateString(dateStr string) string {
+	dateStr = strings.ReplaceAll(dateStr, "\u00a0", " ")
+	return strings.Join(strings.Fields(dateStr), " ")
+}
+
+// fillMissingYear 为不带年份的日期（如 "11月13日"）补上年份，补全后晚于当前时间的视为去年
+func fillMissingYear(date, now time.Time) time.Time {
+	if date.Year() != 0 {
+		return date
+	}
+	date = date.AddDate(now.Year(), 0, 0)
+	if date.After(now.AddDate(0, 0, 1)) {
+		date = date.AddDate(-1, 0, 0)
+	}
+	return date
+}
+
+// parseRelativeDate 解析 "3 hours ago"、"2小时前"、"昨天" 等相对日期
+func parseRelativeDate(dateStr string, now time.Time) (time.Time, bool) {
+	switch strings.ToLower(dateStr) {
+	case "刚刚", "刚才", "just now", "now":
+		return now, true
+	}
+
+	if match := englishAgoPattern.FindStringSubmatch(dateStr); match != nil {
+		amount := 1
+		if n, err := strconv.Atoi(match[1]); err == nil {
+			amount = n
+		}
+		return subtractUnit(now, amount, strings.ToLower(match[2])), true
+	}
+
+	if match := chineseAgoPattern.FindStringSubmatch(dateStr); match != nil {
+		if match[1] == "半" {
+			if match[2] == "小时" || match[2] == "个小时" {
+				return now.Add(-30 * time.Minute), true
+			}
+			return time.Time{}, false
+		}
+		amount := map[string]int{"一": 1, "两": 2, "几": 3}[match[1]]
+		if n, err := strconv.Atoi(match[1]); err == nil {
+			amount = n
+		}
+		return subtractUnit(now, amount, match[2]), true
+	}
+
+	if match := chineseDayPattern.FindStringSubmatch(dateStr); match != nil {
+		days := map[string]int{"今天": 0, "昨天": 1, "前天": 2}[match[1]]
+		return relativeDay(now, days, match[2])
+	}
+
+	if match := englishDayPattern.FindStringSubmatch(dateStr); match != nil {
+		days := map[string]int{"today": 0, "yesterday": 1}[strings.ToLower(match[1])]
+		return relativeDay(now, days, match[2])
+	}
+
+	return time.Time{}, false
+}
+
+// subtractUnit 从当前时间减去指定数量的时间单位
+func subtractUnit(now time.Time, amount int, unit string) time.Time {
+	switch unit {
+	case "second", "sec", "秒":
+		return now.Add(-time.Duration(amount) * time.Second)
+	case "minute", "min", "分钟", "分":
+		return now.Add(-time.Duration(amount) * time.Minute)
+	case "hour", "hr", "小时", "个小时":
+		return now.Add(-time.Duration(amount) * time.Hour)
+	case "day", "天", "日":
+		return now.AddDate(0, 0, -amount)
+	case "week", "周", "星期":
+		return now.AddDate(0, 0, -7*amount)
+	case "month", "个月", "月":
+		return now.AddDate(0, -amount, 0)
+	default:
+		return now.AddDate(-amount, 0, 0)
+	}
+}
+
+// relativeDay 返回 days 天前的日期，带时刻（HH:MM）时使用该时刻，否则为当天零点
+func relativeDay(now time.Time, days int, clock string) (time.Time, bool) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -days)
+	if clock == "" {
+		return day, true
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), true
+}
@@ -0,0 +1,139 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"code/config"
+)
+
+func TestMatchDate(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, shanghai)
+
+	tests := []struct {
+		name    string
+		date    string
+		formats []string
+		want    time.Time
+	}{
+		{"配置的格式", "10/05/2024", []string{"02/01/2006"}, time.Date(2024, 5, 10, 0, 0, 0, 0, shanghai)},
+		{"配置的第二个格式", "2024|05|09", []string{"02/01/2006", "2006|01|02"}, time.Date(2024, 5, 9, 0, 0, 0, 0, shanghai)},
+		{"配置的格式都不匹配时使用内置格式", "2024-05-08", []string{"02/01/2006"}, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"ISO 8601 带时区", "2024-05-08T10:00:00Z", nil, time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+		{"ISO 8601 不带时区", "2024-05-08T10:00:00", nil, time.Date(2024, 5, 8, 10, 0, 0, 0, shanghai)},
+		{"日期和时刻", "2024-05-08 10:00", nil, time.Date(2024, 5, 8, 10, 0, 0, 0, shanghai)},
+		{"斜杠", "2024/05/08", nil, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"点号", "2024.05.08", nil, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"中文日期", "2024年5月8日", nil, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"中文日期和时刻", "2024年5月8日 09:15", nil, time.Date(2024, 5, 8, 9, 15, 0, 0, shanghai)},
+		{"RFC 1123", "Wed, 08 May 2024 10:00:00 GMT", nil, time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+		{"RFC 1123 数字时区", "Wed, 08 May 2024 10:00:00 +0000", nil, time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+		{"英文日期", "May 8, 2024", nil, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"英文全称日期", "8 May 2024", nil, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"不带年份", "5月8日", nil, time.Date(2024, 5, 8, 0, 0, 0, 0, shanghai)},
+		{"不带年份且晚于今天时视为去年", "12月30日", nil, time.Date(2023, 12, 30, 0, 0, 0, 0, shanghai)},
+		{"刚刚", "刚刚", nil, now},
+		{"英文相对时间", "3 hours ago", nil, now.Add(-3 * time.Hour)},
+		{"英文相对时间单数", "an hour ago", nil, now.Add(-time.Hour)},
+		{"英文相对天数", "2 days ago", nil, now.AddDate(0, 0, -2)},
+		{"中文相对时间", "5分钟前", nil, now.Add(-5 * time.Minute)},
+		{"中文相对时间带空格", "3 天前", nil, now.AddDate(0, 0, -3)},
+		{"半小时前", "半小时前", nil, now.Add(-30 * time.Minute)},
+		{"两周前", "两周前", nil, now.AddDate(0, 0, -14)},
+		{"昨天", "昨天", nil, time.Date(2024, 5, 9, 0, 0, 0, 0, shanghai)},
+		{"今天带时刻", "今天 10:30", nil, time.Date(2024, 5, 10, 10, 30, 0, 0, shanghai)},
+		{"前天带时刻", "前天08:05", nil, time.Date(2024, 5, 8, 8, 5, 0, 0, shanghai)},
+		{"yesterday", "Yesterday at 9:00", nil, time.Date(2024, 5, 9, 9, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchDate(normalizeDateString(tt.date), tt.formats, now)
+			if err != nil {
+				t.Fatalf("matchDate(%q): %v", tt.date, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("matchDate(%q) = %s, 期望 %s", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchDateError(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC)
+	_, err := matchDate("not a date", []string{"2006-01-02", "02/01/2006"}, now)
+	if err == nil {
+		t.Fatal("无法解析的日期应返回错误")
+	}
+	// 错误信息列出每个配置格式的失败原因
+	for _, format := range []string{`"2006-01-02"`, `"02/01/2006"`} {
+		if !strings.Contains(err.Error(), format) {
+			t.Errorf("错误信息 %q 缺少格式 %s", err, format)
+		}
+	}
+
+	if _, ok := parseRelativeDate("半年前", now); ok {
+		t.Error("半年前 不应被解析")
+	}
+}
+
+func TestNormalizeDateString(t *testing.T) {
+	got := normalizeDateString("  2024-05-08  10:00 \n")
+	if got != "2024-05-08 10:00" {
+		t.Errorf("normalizeDateString = %q", got)
+	}
+}
+
+func TestParseDateUsesSiteTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		date     string
+		want     time.Time
+	}{
+		{"按站点时区解析不带时区的日期", "America/New_York", "2024-05-08 10:00", time.Date(2024, 5, 8, 10, 0, 0, 0, newYork)},
+		{"日期自带时区时忽略站点时区", "America/New_York", "2024-05-08T10:00:00Z", time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+		{"未配置时区时按 UTC 解析", "", "2024-05-08 10:00", time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+		{"时区无效时按 UTC 解析", "Mars/Olympus", "2024-05-08 10:00", time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate(tt.date, config.SiteConfig{Timezone: tt.timezone})
+			if err != nil {
+				t.Fatalf("parseDate(%q): %v", tt.date, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDate(%q) = %s, 期望 %s", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchDateRelativeToSiteTimezone(t *testing.T) {
+	tokyo := config.SiteConfig{Timezone: "Asia/Tokyo"}.Location()
+	if tokyo == time.UTC {
+		t.Skip("缺少时区数据")
+	}
+	// UTC 15:30 在东京已是次日 00:30，相对日期按站点时区的日历日计算
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC).In(tokyo)
+	got, err := matchDate("今天", nil, now)
+	if err != nil {
+		t.Fatalf("matchDate: %v", err)
+	}
+	want := time.Date(2024, 5, 11, 0, 0, 0, 0, tokyo)
+	if !got.Equal(want) {
+		t.Errorf("matchDate(今天) = %s, 期望站点时区的零点 %s", got, want)
+	}
+}
+
+func TestParseDateEmpty(t *testing.T) {
+	if _, err := parseDate("   ", config.SiteConfig{}); err == nil {
+		t.Error("空日期应返回错误")
+	}
+}
@@ -0,0 +1,296 @@
+package parse
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	htmlparser "golang.org/x/net/html"
+	"golang.org/x/text/encoding/htmlindex"
+
+	"code/config"
+)
+
+// feedItem 是从不同格式的订阅源中提取出的单条内容
+type feedItem struct {
+	Title string
+	Link  string
+	Date  string
+}
+
+// rssFeed 同时兼容 RSS 2.0（item 在 channel 下）与 RSS 1.0/RDF（item 在根元素下）
+type rssFeed struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	DCDate  string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomFeed struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+// atomText 是 Atom 的文本构造，type 为 text（默认）、html 或 xhtml
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// plain 返回文本构造中带实体转义的纯文本：html 类型的内容是转义后的 HTML 标记，xhtml 类型的内容是内嵌的 XHTML 元素，都需要去掉标签
+func (t atomText) plain() string {
+	switch strings.ToLower(t.Type) {
+	case "html":
+		return stripTags(t.Text)
+	case "xhtml":
+		return stripTags(t.Inner)
+	default:
+		return t.Text
+	}
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type jsonFeed struct {
+	Items []struct {
+		ID            string `json:"id"`
+		URL           string `json:"url"`
+		ExternalURL   string `json:"external_url"`
+		Title         string `json:"title"`
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+	} `json:"items"`
+}
+
+// parseFeedItems 解析 RSS、Atom 或 JSON Feed 订阅源，返回解析成功的条目和单条失败的原因
+func parseFeedItems(content string, siteConfig config.SiteConfig) ([]Result, []string, error) {
+	items, err := decodeFeed(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil, fmt.Errorf("订阅源中没有任何条目")
+	}
+
+	var results []Result
+	var errs []string
+	for i, item := range items {
+		result, err := parseFeedItem(item, siteConfig)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("第 %d 条: %v", i+1, err))
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results, errs, nil
+}
+
+// parseFeedItem 将订阅源中的单条内容转换为 Result
+func parseFeedItem(item feedItem, siteConfig config.SiteConfig) (*Result, error) {
+	title := strings.TrimSpace(html.UnescapeString(item.Title))
+	if title == "" {
+		return nil, fmt.Errorf("标题为空")
+	}
+	if strings.TrimSpace(item.Link) == "" {
+		return nil, fmt.Errorf("未找到链接")
+	}
+
+	endpoint, err := resolveFeedURL(item.Link, siteConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// 部分订阅源不提供日期，此时保留零值
+	var date time.Time
+	if strings.TrimSpace(item.Date) != "" {
+		date, err = parseDate(item.Date, siteConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Result{
+		Title:    title,
+		Endpoint: endpoint,
+		Date:     date,
+	}, nil
+}
+
+// resolveFeedURL 将订阅源中的相对链接按订阅源地址解析为完整URL，配置了 real_url 时与 HTML 站点的处理一致
+func resolveFeedURL(link string, siteConfig config.SiteConfig) (string, error) {
+	if siteConfig.RealURL != "" {
+		return resolveURL(link, siteConfig)
+	}
+
+	baseURL, err := url.Parse(siteConfig.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("base_url 解析错误: %v", err)
+	}
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", fmt.Errorf("链接解析错误: %v", err)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+// decodeFeed 根据内容自动识别订阅源格式并提取条目
+func decodeFeed(content string) ([]feedItem, error) {
+	content = strings.TrimSpace(strings.TrimPrefix(content, "\ufeff"))
+	if strings.HasPrefix(content, "{") {
+		return decodeJSONFeed(content)
+	}
+
+	rootName, err := feedRootName(content)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rootName {
+	case "rss", "RDF":
+		return decodeRSS(content)
+	case "feed":
+		return decodeAtom(content)
+	default:
+		return nil, fmt.Errorf("无法识别的订阅源格式: <%s>", rootName)
+	}
+}
+
+// newFeedDecoder 创建支持非 UTF-8 编码声明的 XML 解码器
+func newFeedDecoder(content string) *xml.Decoder {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		encoding, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, fmt.Errorf("不支持的编码 %s: %v", charset, err)
+		}
+		return encoding.NewDecoder().Reader(input), nil
+	}
+	return decoder
+}
+
+// feedRootName 返回 XML 文档根元素的名称
+func feedRootName(content string) (string, error) {
+	decoder := newFeedDecoder(content)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("订阅源解析错误: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func decodeRSS(content string) ([]feedItem, error) {
+	var feed rssFeed
+	if err := newFeedDecoder(content).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("RSS 解析错误: %v", err)
+	}
+
+	var items []feedItem
+	for _, item := range append(feed.Channel.Items, feed.Items...) {
+		link := item.Link
+		if link == "" && strings.HasPrefix(item.GUID, "http") {
+			link = item.GUID
+		}
+		date := item.PubDate
+		if date == "" {
+			date = item.DCDate
+		}
+		items = append(items, feedItem{Title: item.Title, Link: strings.TrimSpace(link), Date: date})
+	}
+	return items, nil
+}
+
+func decodeAtom(content string) ([]feedItem, error) {
+	var feed atomFeed
+	if err := newFeedDecoder(content).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("Atom 解析错误: %v", err)
+	}
+
+	var items []feedItem
+	for _, entry := range feed.Entries {
+		// 优先使用 rel="alternate"（或未指定 rel）的链接
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		if link == "" && len(entry.Links) > 0 {
+			link = entry.Links[0].Href
+		}
+		date := entry.Published
+		if date == "" {
+			date = entry.Updated
+		}
+		items = append(items, feedItem{Title: entry.Title.plain(), Link: link, Date: date})
+	}
+	return items, nil
+}
+
+// stripTags 去掉 HTML 标记，保留文本中的实体转义（由 parseFeedItem 统一反转义），并合并连续空白
+func stripTags(markup string) string {
+	var text strings.Builder
+	tokenizer := htmlparser.NewTokenizer(strings.NewReader(markup))
+	for {
+		switch tokenizer.Next() {
+		case htmlparser.ErrorToken:
+			return strings.Join(strings.Fields(text.String()), " ")
+		case htmlparser.TextToken:
+			text.Write(tokenizer.Raw())
+		case htmlparser.StartTagToken, htmlparser.SelfClosingTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "br" {
+				text.WriteByte(' ')
+			}
+		}
+	}
+}
+
+func decodeJSONFeed(content string) ([]feedItem, error) {
+	var feed jsonFeed
+	if err := json.Unmarshal([]byte(content), &feed); err != nil {
+		return nil, fmt.Errorf("JSON Feed 解析错误: %v", err)
+	}
+
+	var items []feedItem
+	for _, item := range feed.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		date := item.DatePublished
+		if date == "" {
+			date = item.DateModified
+		}
+		items = append(items, feedItem{Title: item.Title, Link: link, Date: date})
+	}
+	return items, nil
+}
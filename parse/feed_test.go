@@ -0,0 +1,99 @@
+package parse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code/config"
+)
+
+func TestParseFeedFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		want     []Result
+		wantErrs int
+	}{
+		{
+			file: "rss.xml",
+			want: []Result{
+				{Title: "Rates & bonds", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+				{Title: "Chips & GPUs", Endpoint: "https://example.com/news/2", Date: time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC)},
+				{Title: "Relative link", Endpoint: "https://example.com/news/3"},
+			},
+			wantErrs: 1,
+		},
+		{
+			file: "rss_gbk.xml",
+			want: []Result{
+				{Title: "英伟达发布新 GPU", Endpoint: "https://example.com/news/gbk", Date: time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			file: "atom.xml",
+			want: []Result{
+				{Title: "Plain & simple", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+				{Title: "Bold & more", Endpoint: "https://example.com/news/2", Date: time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC)},
+				{Title: "An XHTML title&co", Endpoint: "https://example.com/news/3", Date: time.Date(2024, 5, 6, 2, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			file: "feed.json",
+			want: []Result{
+				{Title: "First & foremost", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 8, 10, 0, 0, 0, time.UTC)},
+				{Title: "External", Endpoint: "https://other.example.com/2", Date: time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC)},
+			},
+			wantErrs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatalf("读取测试数据: %v", err)
+			}
+			site := config.SiteConfig{Name: "feed", Type: config.SiteTypeFeed, BaseURL: "https://example.com/feed.xml"}
+
+			results, errs, err := parseFeedItems(string(content), site)
+			if err != nil {
+				t.Fatalf("parseFeedItems: %v", err)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("单条失败 %d 条 (%v), 期望 %d 条", len(errs), errs, tt.wantErrs)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("解析出 %d 条, 期望 %d 条: %+v", len(results), len(tt.want), results)
+			}
+			for i := range tt.want {
+				if results[i].Title != tt.want[i].Title || results[i].Endpoint != tt.want[i].Endpoint || !results[i].Date.Equal(tt.want[i].Date) {
+					t.Errorf("第 %d 条 = %+v, 期望 %+v", i+1, results[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseAllFeedSite(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "atom.xml"))
+	if err != nil {
+		t.Fatalf("读取测试数据: %v", err)
+	}
+	site := config.SiteConfig{Name: "feed", Type: config.SiteTypeFeed, BaseURL: "https://example.com/feed.xml"}
+	results, err := ParseAll(context.Background(), string(content), site)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+	if results[1].OriginalTitle != "Bold & more" {
+		t.Errorf("OriginalTitle = %q, 期望 %q", results[1].OriginalTitle, "Bold & more")
+	}
+}
+
+func TestDecodeFeedInvalid(t *testing.T) {
+	for _, content := range []string{`<html><body>not a feed</body></html>`, `{not json`, `<rss`} {
+		if _, err := decodeFeed(content); err == nil {
+			t.Errorf("decodeFeed(%q) 应返回错误", content)
+		}
+	}
+}
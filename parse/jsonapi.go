@@ -0,0 +1,130 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code/config"
+)
+
+// parseJSONItems 按站点 api 配置中的 JSONPath 映射解析接口返回的 JSON，返回解析成功的条目和单条失败的原因
+func parseJSONItems(content string, siteConfig config.SiteConfig) ([]Result, []string, error) {
+	api := siteConfig.API
+	if api == nil {
+		return nil, nil, fmt.Errorf("json 类型站点缺少 api 配置")
+	}
+
+	var data interface{}
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, nil, fmt.Errorf("JSON 解析错误: %v", err)
+	}
+
+	items, err := evalJSONPath(data, api.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+	// items 指向数组本身时（如 $.data.items）展开为数组元素
+	if len(items) == 1 {
+		if list, ok := items[0].([]interface{}); ok {
+			items = list
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil, fmt.Errorf("未找到符合条目路径 (%s) 的元素", api.Items)
+	}
+
+	var results []Result
+	var errs []string
+	for i, item := range items {
+		result, err := parseJSONItem(item, siteConfig)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("第 %d 条: %v", i+1, err))
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results, errs, nil
+}
+
+// parseJSONItem 从单个 JSON 条目中提取标题、链接和日期
+func parseJSONItem(item interface{}, siteConfig config.SiteConfig) (*Result, error) {
+	api := siteConfig.API
+
+	title, err := jsonField(item, api.Title)
+	if err != nil {
+		return nil, fmt.Errorf("未找到标题: %v", err)
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("标题为空")
+	}
+
+	link, err := jsonField(item, api.Link)
+	if err != nil {
+		return nil, fmt.Errorf("未找到链接: %v", err)
+	}
+	endpoint, err := resolveURL(link, siteConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未配置日期字段时保留零值
+	var date time.Time
+	if api.Date != "" {
+		dateStr, err := jsonField(item, api.Date)
+		if err != nil {
+			return nil, fmt.Errorf("未找到日期: %v", err)
+		}
+		date, err = parseJSONDate(dateStr, siteConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Result{
+		Title:    title,
+		Endpoint: endpoint,
+		Date:     date,
+	}, nil
+}
+
+// jsonField 取条目中 JSONPath 对应的第一个值并转换为字符串
+func jsonField(item interface{}, path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("未配置字段路径")
+	}
+	values, err := evalJSONPath(item, path)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 || values[0] == nil {
+		return "", fmt.Errorf("路径 %s 没有匹配的值", path)
+	}
+
+	switch v := values[0].(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("路径 %s 的值不是字符串或数字", path)
+	}
+}
+
+// parseJSONDate 解析接口返回的日期，纯数字按 Unix 时间戳（秒或毫秒）处理
+func parseJSONDate(dateStr string, siteConfig config.SiteConfig) (time.Time, error) {
+	if timestamp, err := strconv.ParseInt(strings.TrimSpace(dateStr), 10, 64); err == nil {
+		if timestamp > 1e12 {
+			return time.UnixMilli(timestamp), nil
+		}
+		return time.Unix(timestamp, 0), nil
+	}
+	return parseDate(dateStr, siteConfig)
+}
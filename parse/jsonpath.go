@@ -0,0 +1,118 @@
+package parse
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// evalJSONPath 在解码后的 JSON 数据上执行 JSONPath 表达式，返回所有匹配的值
+// 支持的语法：$ 根节点、.key 与 ['key'] 取字段、[n] 取下标（支持负数）、[*] 与 .* 通配（对象按字段名顺序）
+// 表达式可以省略开头的 $，此时视为相对于传入的数据
+func evalJSONPath(data interface{}, path string) ([]interface{}, error) {
+	steps, err := splitJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []interface{}{data}
+	for _, step := range steps {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, applyJSONPathStep(value, step)...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// splitJSONPath 将 JSONPath 表达式拆分为逐级的字段名、下标或通配符
+func splitJSONPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	var steps []string
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("JSONPath 表达式无效: 空字段名")
+			}
+			steps = append(steps, path[:end])
+			path = path[end:]
+		case '[':
+			end := strings.Index(path, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("JSONPath 表达式无效: 缺少 ]")
+			}
+			step := strings.TrimSpace(path[1:end])
+			if len(step) >= 2 && (step[0] == '\'' || step[0] == '"') && step[len(step)-1] == step[0] {
+				// 带引号的字段名，与下标区分开
+				step = "." + step[1:len(step)-1]
+			}
+			steps = append(steps, step)
+			path = path[end+1:]
+		default:
+			// 省略 $ 和开头点号的相对表达式，例如 title 或 data.items
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			steps = append(steps, path[:end])
+			path = path[end:]
+		}
+	}
+	return steps, nil
+}
+
+// applyJSONPathStep 对单个值执行一级 JSONPath 步骤
+func applyJSONPathStep(value interface{}, step string) []interface{} {
+	if step == "*" {
+		switch v := value.(type) {
+		case []interface{}:
+			return v
+		case map[string]interface{}:
+			// 解码后的对象不保留字段顺序，按字段名排序，保证每次返回的顺序一致
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			values := make([]interface{}, 0, len(v))
+			for _, key := range keys {
+				values = append(values, v[key])
+			}
+			return values
+		}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		index, err := strconv.Atoi(step)
+		if err != nil {
+			return nil
+		}
+		if index < 0 {
+			index += len(v)
+		}
+		if index < 0 || index >= len(v) {
+			return nil
+		}
+		return []interface{}{v[index]}
+	case map[string]interface{}:
+		// 带引号的字段名以 . 开头，始终按字段名处理
+		item, ok := v[strings.TrimPrefix(step, ".")]
+		if !ok {
+			return nil
+		}
+		return []interface{}{item}
+	}
+	return nil
+}
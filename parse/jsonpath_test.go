@@ -0,0 +1,159 @@
+package parse
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"code/config"
+)
+
+// decodeJSON 按 parseJSONItems 的方式解码测试数据，数字保留为 json.Number
+func decodeJSON(t *testing.T, content string) interface{} {
+	t.Helper()
+	var data interface{}
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		t.Fatalf("测试数据无法解析: %v", err)
+	}
+	return data
+}
+
+func TestEvalJSONPath(t *testing.T) {
+	data := decodeJSON(t, `{
+		"data": {
+			"items": [
+				{"title": "first", "id": 1},
+				{"title": "second", "id": 2},
+				{"title": "third", "id": 3}
+			],
+			"by.dot": "quoted",
+			"groups": {"b": {"title": "in b"}, "a": {"title": "in a"}, "c": {"title": "in c"}}
+		}
+	}`)
+
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.data.items[0].title", []interface{}{"first"}},
+		{"data.items[1].title", []interface{}{"second"}},
+		{"$.data.items[-1].title", []interface{}{"third"}},
+		{"$.data.items[*].id", []interface{}{json.Number("1"), json.Number("2"), json.Number("3")}},
+		{"$.data.items.*.title", []interface{}{"first", "second", "third"}},
+		{"$['data']['by.dot']", []interface{}{"quoted"}},
+		{`$["data"]["items"][2]["title"]`, []interface{}{"third"}},
+		// 对象通配按字段名排序
+		{"$.data.groups.*.title", []interface{}{"in a", "in b", "in c"}},
+		{"$.data.groups[*].title", []interface{}{"in a", "in b", "in c"}},
+		{"$.data.items[3].title", nil},
+		{"$.data.items[-4].title", nil},
+		{"$.data.items.title", nil},
+		{"$.data.missing", nil},
+		{"$.data.items[0].title.more", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := evalJSONPath(data, tt.path)
+			if err != nil {
+				t.Fatalf("evalJSONPath(%q): %v", tt.path, err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evalJSONPath(%q) = %v, 期望 %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalJSONPathRoot(t *testing.T) {
+	data := decodeJSON(t, `[1, 2]`)
+	for _, path := range []string{"$", ""} {
+		got, err := evalJSONPath(data, path)
+		if err != nil {
+			t.Fatalf("evalJSONPath(%q): %v", path, err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(got[0], data) {
+			t.Errorf("evalJSONPath(%q) = %v, 期望返回根节点", path, got)
+		}
+	}
+}
+
+func TestEvalJSONPathObjectWildcardIsStable(t *testing.T) {
+	data := decodeJSON(t, `{"z": 1, "m": 2, "a": 3, "q": 4, "c": 5, "x": 6}`)
+	first, err := evalJSONPath(data, "$.*")
+	if err != nil {
+		t.Fatalf("evalJSONPath: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		got, _ := evalJSONPath(data, "$.*")
+		if !reflect.DeepEqual(got, first) {
+			t.Fatalf("第 %d 次结果 %v 与第一次 %v 不同", i+2, got, first)
+		}
+	}
+}
+
+func TestEvalJSONPathInvalid(t *testing.T) {
+	for _, path := range []string{"$.data.", "$.data[0", "$..title"} {
+		if _, err := evalJSONPath(nil, path); err == nil {
+			t.Errorf("evalJSONPath(%q) 应返回错误", path)
+		}
+	}
+}
+
+func TestParseJSONItems(t *testing.T) {
+	site := config.SiteConfig{
+		Name:    "api",
+		BaseURL: "https://example.com",
+		API: &config.APIConfig{
+			Items: "$.data.list",
+			Title: "$.title",
+			Link:  "$.url",
+			Date:  "$.published",
+		},
+	}
+	content := `{"data": {"list": [
+		{"title": " Absolute ", "url": "https://news.example.com/a", "published": "2024-05-01T08:00:00Z"},
+		{"title": "Relative", "url": "/news/b", "published": 1714550400},
+		{"title": "Millis", "url": "/news/c", "published": "1714550400000"},
+		{"title": "", "url": "/news/d"},
+		{"title": "No link"}
+	]}}`
+
+	results, errs, err := parseJSONItems(content, site)
+	if err != nil {
+		t.Fatalf("parseJSONItems: %v", err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("单条失败 %d 条 (%v), 期望 2 条", len(errs), errs)
+	}
+
+	want := []Result{
+		{Title: "Absolute", Endpoint: "https://news.example.com/a", Date: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{Title: "Relative", Endpoint: "https://example.com/news/b", Date: time.Unix(1714550400, 0)},
+		{Title: "Millis", Endpoint: "https://example.com/news/c", Date: time.Unix(1714550400, 0)},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("解析出 %d 条, 期望 %d 条: %+v", len(results), len(want), results)
+	}
+	for i := range want {
+		if results[i].Title != want[i].Title || results[i].Endpoint != want[i].Endpoint || !results[i].Date.Equal(want[i].Date) {
+			t.Errorf("第 %d 条 = %+v, 期望 %+v", i+1, results[i], want[i])
+		}
+	}
+}
+
+func TestParseJSONItemsNoMatch(t *testing.T) {
+	site := config.SiteConfig{API: &config.APIConfig{Items: "$.items[*]", Title: "$.title", Link: "$.url"}}
+	if _, _, err := parseJSONItems(`{"data": []}`, site); err == nil {
+		t.Error("条目路径没有匹配时应返回错误")
+	}
+	if _, _, err := parseJSONItems(`not json`, site); err == nil {
+		t.Error("JSON 无效时应返回错误")
+	}
+}
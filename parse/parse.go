@@ -1,6 +1,7 @@
 package parse
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/url"
@@ -8,169 +9,187 @@ import (
 	"time"
 
 	"github.com/anaskhan96/soup"
-
-	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
-	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
-	tmt "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tmt/v20180321"
+	"golang.org/x/net/html"
 
 	"code/config"
 )
 
 type Result struct {
-	Title    string
+	Title    string // 推送时显示的标题，翻译后为译文
 	Endpoint string
 	Date     time.Time
+
+	OriginalTitle    string // 页面上的原始标题
+	DetectedLanguage string // 原始标题的语言，由翻译环节填写
+	TranslatedTitle  string // 标题译文，未翻译时为空
 }
 
-// translate 调用腾讯云翻译API，将文本翻译成目标语言
-func translate(text, targetLang string) (string, error) {
-	// 加载配置文件
-	cfg, err := config.LoadConfig("config/config.yaml")
+// Parse 解析HTML内容，提取列表页中最新一条的标题、日期和链接
+func Parse(ctx context.Context, htmlContent string, siteConfig config.SiteConfig) (*Result, error) {
+	results, err := ParseAll(ctx, htmlContent, siteConfig)
 	if err != nil {
-		return text, fmt.Errorf("加载配置失败: %v", err)
+		return nil, err
 	}
+	return &results[0], nil
+}
 
-	// 创建腾讯云客户端
-	client, err := newTencentClient(cfg)
-	if err != nil {
-		return text, err
+// ParseAll 解析HTML内容，提取列表页中每一条新闻的标题、日期和链接
+// feed 类型的站点按 RSS/Atom/JSON Feed 解析，json 类型的站点按 api 中的 JSONPath 映射解析；HTML 站点配置了 selectors 时使用 CSS/XPath 选择器规则，否则使用 parse_rules 中的标签规则
+// 返回结果保持页面中的顺序（通常为从新到旧），单条解析失败时跳过该条；标题不做翻译。ctx 已取消时不再解析
+func ParseAll(ctx context.Context, htmlContent string, siteConfig config.SiteConfig) ([]Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// 调用翻译API
-	request := tmt.NewTextTranslateRequest()
-	request.SourceText = common.StringPtr(text)
-	request.Source = common.StringPtr("auto")
-	request.Target = common.StringPtr(targetLang)
-	request.ProjectId = common.Int64Ptr(0) // 默认项目ID
-
-	response, err := client.TextTranslate(request)
+	var results []Result
+	var errs []string
+	var err error
+	switch {
+	case siteConfig.Type == config.SiteTypeFeed:
+		results, errs, err = parseFeedItems(htmlContent, siteConfig)
+	case siteConfig.Type == config.SiteTypeJSON:
+		results, errs, err = parseJSONItems(htmlContent, siteConfig)
+	case siteConfig.Selectors != nil:
+		results, errs, err = parseSelectorItems(htmlContent, siteConfig)
+	default:
+		results, errs, err = parseRuleItems(htmlContent, siteConfig)
+	}
 	if err != nil {
-		return text, fmt.Errorf("翻译请求失败: %v", err)
+		return nil, err
 	}
 
-	// 返回翻译后的文本
-	return *response.Response.TargetText, nil
-}
-
-// newTencentClient 创建并配置腾讯云客户端
-func newTencentClient(cfg *config.Config) (*tmt.Client, error) {
-	credential := common.NewCredential(
-		cfg.TencentParams.SecretID,
-		cfg.TencentParams.SecretKey,
-	)
-
-	cpf := profile.NewClientProfile()
-	cpf.HttpProfile.Endpoint = "tmt.tencentcloudapi.com" // 腾讯云翻译API端点
-
-	client, err := tmt.NewClient(credential, "ap-guangzhou", cpf)
-	if err != nil {
-		return nil, fmt.Errorf("创建 TMT 客户端失败: %v", err)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("未能解析出任何条目: %s", strings.Join(errs, "; "))
+	}
+	for i := range results {
+		results[i].OriginalTitle = results[i].Title
+	}
+	if len(errs) > 0 {
+		log.Printf("站点 %s 有 %d 条内容解析失败: %s", siteConfig.Name, len(errs), strings.Join(errs, "; "))
 	}
-	return client, nil
-}
 
-// Parse 解析HTML内容，提取标题、日期和链接
-func Parse(htmlContent string, siteConfig config.SiteConfig) (*Result, error) {
-	// 初始化结果结构体
-	result := &Result{}
+	return results, nil
+}
 
+// parseRuleItems 使用 parse_rules 中的标签规则解析列表页，返回解析成功的条目和单条失败的原因
+func parseRuleItems(htmlContent string, siteConfig config.SiteConfig) ([]Result, []string, error) {
 	// 解析HTML内容
 	doc := soup.HTMLParse(htmlContent)
 	if doc.Error != nil {
-		return nil, fmt.Errorf("HTML解析错误: %v", doc.Error)
+		return nil, nil, fmt.Errorf("HTML解析错误: %v", doc.Error)
 	}
 
 	// 获取文章内容
 	paragraphs, err := getContent(doc, siteConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	// 日期不在内容元素中时，按顺序与内容元素一一对应
+	var dateElements []soup.Root
+	if siteConfig.ParseRules["date_in"] != "yes" {
+		dateElements = doc.FindAll(siteConfig.ParseRules["date_tag"], siteConfig.ParseRules["date_mode"], siteConfig.ParseRules["date"])
+	}
+
+	var results []Result
+	var errs []string
+	for i, paragraph := range paragraphs {
+		result, err := parseItem(paragraph, i, dateElements, siteConfig)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("第 %d 条: %v", i+1, err))
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results, errs, nil
+}
+
+// parseItem 从单个内容元素中提取标题、日期和链接
+func parseItem(paragraph soup.Root, index int, dateElements []soup.Root, siteConfig config.SiteConfig) (*Result, error) {
 	// 提取日期
-	date, err := getDate(paragraphs, doc, siteConfig)
+	date, err := getDate(paragraph, index, dateElements, siteConfig)
 	if err != nil {
 		return nil, err
 	}
-	result.Date = date
 
 	// 提取标题和链接
-	title, endpoint, err := getTitleAndEndpoint(paragraphs, siteConfig)
+	title, endpoint, err := getTitleAndEndpoint(paragraph, siteConfig)
 	if err != nil {
 		return nil, err
 	}
-	result.Title = title
-	result.Endpoint = endpoint
 
-	// 翻译标题
-	translatedTitle, err := translate(result.Title, "zh")
-	if err != nil {
-		log.Printf("标题翻译失败: %v", err)
-	}
-	result.Title = translatedTitle
-
-	// 返回结果
-	fmt.Printf("Title: %s\nEndpoint: %s\nDate: %s\n", result.Title, result.Endpoint, result.Date.Format("January 2, 2006"))
-	return result, nil
+	return &Result{
+		Title:    strings.TrimSpace(title),
+		Endpoint: endpoint,
+		Date:     date,
+	}, nil
 }
 
-// getContent 获取文章内容
+// getContent 获取文章内容，content 中逗号分隔的多个类名命中的元素按页面顺序返回，同时命中多个类名的元素只返回一次
 func getContent(doc soup.Root, siteConfig config.SiteConfig) ([]soup.Root, error) {
 	contentClasses := strings.Split(siteConfig.ParseRules["content"], ",")
-	var paragraphs []soup.Root
+	matched := make(map[*html.Node]bool)
 	for _, className := range contentClasses {
-		paragraphs = append(paragraphs, doc.FindAll(siteConfig.ParseRules["content_tag"], siteConfig.ParseRules["content_mode"], className)...)
+		for _, element := range doc.FindAll(siteConfig.ParseRules["content_tag"], siteConfig.ParseRules["content_mode"], className) {
+			matched[element.Pointer] = true
+		}
 	}
 
-	if len(paragraphs) == 0 {
+	if len(matched) == 0 {
 		return nil, fmt.Errorf("未找到符合内容选择器 (%s) 的元素", siteConfig.ParseRules["content"])
 	}
-	return paragraphs, nil
+	return inDocumentOrder(doc.Pointer, matched), nil
 }
 
-// getDate 提取并解析文章日期
-func getDate(paragraphs []soup.Root, doc soup.Root, siteConfig config.SiteConfig) (time.Time, error) {
+// inDocumentOrder 按深度优先遍历的顺序（即页面中的顺序）返回 root 下属于 matched 的节点
+func inDocumentOrder(root *html.Node, matched map[*html.Node]bool) []soup.Root {
+	elements := make([]soup.Root, 0, len(matched))
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if matched[n] {
+			elements = append(elements, soup.Root{Pointer: n, NodeValue: n.Data})
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return elements
+}
+
+// getDate 提取并解析单条内容的日期
+func getDate(paragraph soup.Root, index int, dateElements []soup.Root, siteConfig config.SiteConfig) (time.Time, error) {
 	dateTags := strings.Split(siteConfig.ParseRules["date_tag"], ",")
 	var dateElement soup.Root
 
 	if siteConfig.ParseRules["date_in"] == "yes" {
-		dateElement = findDateInParagraphs(paragraphs, dateTags)
+		dateElement = findDateInParagraph(paragraph, dateTags)
 	} else {
-		dateElement = doc.Find(siteConfig.ParseRules["date_tag"], siteConfig.ParseRules["date_mode"], siteConfig.ParseRules["date"])
+		if index >= len(dateElements) {
+			return time.Time{}, fmt.Errorf("未找到日期元素: 第 %d 条没有对应的日期", index+1)
+		}
+		dateElement = dateElements[index]
 	}
 
 	if dateElement.Error != nil {
 		return time.Time{}, fmt.Errorf("未找到日期元素: %v", dateElement.Error)
 	}
 
-	dateStr := strings.TrimSpace(dateElement.Text())
-	if dateStr == "" {
-		return time.Time{}, fmt.Errorf("日期为空")
-	}
-
-	// 解析日期
-	date, err := time.Parse(siteConfig.DateFormats[0], dateStr)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("日期解析错误: %v", err)
-	}
-
-	// 日期对比
-	compareDate, err := time.Parse("2006年01月02日", "2024年10月24日")
-	if err != nil {
-		return time.Time{}, fmt.Errorf("对比日期解析错误: %v", err)
-	}
-
-	if date.Before(compareDate) {
-		return time.Time{}, nil // 如果日期早于对比日期，则跳过
+	// <time datetime="..."> 等带机器可读日期的元素优先使用属性值
+	if datetime := dateElement.Attrs()["datetime"]; datetime != "" {
+		if date, err := parseDate(datetime, siteConfig); err == nil {
+			return date, nil
+		}
 	}
 
-	return date, nil
+	return parseDate(dateElement.Text(), siteConfig)
 }
 
-// findDateInParagraphs 在文章中查找日期
-func findDateInParagraphs(paragraphs []soup.Root, dateTags []string) soup.Root {
+// findDateInParagraph 在单条内容中查找日期
+func findDateInParagraph(paragraph soup.Root, dateTags []string) soup.Root {
 	var dateElement soup.Root
 	for _, dateTag := range dateTags {
-		dateElement = paragraphs[0].Find(dateTag)
+		dateElement = paragraph.Find(dateTag)
 		if dateElement.Error == nil {
 			break
 		}
@@ -179,20 +198,20 @@ func findDateInParagraphs(paragraphs []soup.Root, dateTags []string) soup.Root {
 }
 
 // getTitleAndEndpoint 提取标题和链接
-func getTitleAndEndpoint(paragraphs []soup.Root, siteConfig config.SiteConfig) (string, string, error) {
+func getTitleAndEndpoint(paragraph soup.Root, siteConfig config.SiteConfig) (string, string, error) {
 	var titleElement soup.Root
 	var title string
 	if siteConfig.ParseRules["title_mode"] == "class" {
 		// 处理 title_class 配置
 		titleClasses := strings.Split(siteConfig.ParseRules["title"], ",")
 		for _, className := range titleClasses {
-			titleElement = paragraphs[0].Find(siteConfig.ParseRules["title_tag"], "class", className)
+			titleElement = paragraph.Find(siteConfig.ParseRules["title_tag"], "class", className)
 			if titleElement.Error == nil {
 				break
 			}
 		}
 	} else if siteConfig.ParseRules["title_mode"] == "" {
-		titleElement = paragraphs[0]
+		titleElement = paragraph
 	}
 
 	if titleElement.Error != nil {
@@ -219,10 +238,20 @@ func getTitleAndEndpoint(paragraphs []soup.Root, siteConfig config.SiteConfig) (
 		title = aElement.Text()
 	}
 
-	// 拼接完整URL
+	fullURL, err := resolveURL(relativeURL, siteConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	return title, fullURL, nil
+}
+
+// resolveURL 将页面中的相对链接拼接为完整URL
+func resolveURL(relativeURL string, siteConfig config.SiteConfig) (string, error) {
+	relativeURL = strings.TrimSpace(relativeURL)
 	parsedURL, err := url.Parse(relativeURL)
 	if err != nil {
-		return "", "", fmt.Errorf("链接解析错误: %v", err)
+		return "", fmt.Errorf("链接解析错误: %v", err)
 	}
 
 	var fullURL string
@@ -236,7 +265,7 @@ func getTitleAndEndpoint(paragraphs []soup.Root, siteConfig config.SiteConfig) (
 		fullURL = relativeURL
 	}
 
-	return title, fullURL, nil
+	return fullURL, nil
 }
 
 // 去除 URL 中重复的路径部分
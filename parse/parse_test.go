@@ -0,0 +1,50 @@
+package parse
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"code/config"
+)
+
+func TestParseAllMultiClass(t *testing.T) {
+	site := config.SiteConfig{
+		Name:    "multi",
+		BaseURL: "https://example.com",
+		ParseRules: map[string]string{
+			"content":      "list,list_1,list_2",
+			"content_tag":  "li",
+			"content_mode": "class",
+			"date_in":      "yes",
+			"date_tag":     "span",
+		},
+	}
+	// A 同时带有 list 和 list_1，只应出现一次；各类名的元素交错排列，结果按页面顺序
+	content := `<html><body><ul>
+		<li class="list list_1"><a href="https://example.com/a">A</a><span>2024-05-04</span></li>
+		<li class="list_2"><a href="https://example.com/b">B</a><span>2024-05-03</span></li>
+		<li class="list"><a href="https://example.com/c">C</a><span>2024-05-02</span></li>
+		<li class="list_1"><a href="https://example.com/d">D</a><span>2024-05-01</span></li>
+		<li class="other"><a href="https://example.com/e">E</a><span>2024-04-30</span></li>
+	</ul></body></html>`
+
+	results, err := ParseAll(context.Background(), content, site)
+	if err != nil {
+		t.Fatalf("ParseAll: %v", err)
+	}
+	var titles []string
+	for _, result := range results {
+		titles = append(titles, result.Title)
+	}
+	if want := []string{"A", "B", "C", "D"}; !slices.Equal(titles, want) {
+		t.Errorf("标题 = %v, 期望 %v", titles, want)
+	}
+}
+
+func TestParseAllNoContent(t *testing.T) {
+	site := config.SiteConfig{ParseRules: map[string]string{"content": "list", "content_tag": "li", "content_mode": "class"}}
+	if _, err := ParseAll(context.Background(), `<ul><li class="other">x</li></ul>`, site); err == nil {
+		t.Error("没有符合内容选择器的元素时应返回错误")
+	}
+}
@@ -0,0 +1,273 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+
+	"code/config"
+)
+
+// selectorNode 对 CSS 与 XPath 两种选择器下的页面元素做统一抽象
+type selectorNode interface {
+	// findAll 查找元素内匹配选择器的所有元素，选择器为空时返回元素本身
+	findAll(selector string) ([]selectorNode, error)
+	// text 返回元素文本
+	text() string
+	// attr 返回元素属性值
+	attr(name string) (string, bool)
+	// link 返回元素本身或其中第一个 <a> 的 href
+	link() (string, bool)
+}
+
+// parseSelectorItems 使用 selectors 中的 CSS/XPath 规则解析列表页，返回解析成功的条目和单条失败的原因
+func parseSelectorItems(htmlContent string, siteConfig config.SiteConfig) ([]Result, []string, error) {
+	rules := siteConfig.Selectors
+
+	root, err := newSelectorRoot(rules.Type, htmlContent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := root.findAll(rules.Item)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil, fmt.Errorf("未找到符合内容选择器 (%s) 的元素", rules.Item)
+	}
+
+	// scope 为 document 的字段在整个页面中查找，按顺序与条目一一对应
+	documentMatches := make(map[string][]selectorNode)
+	for _, field := range []config.FieldSelector{rules.Title, rules.Link, rules.Date} {
+		if field.Scope != config.ScopeDocument {
+			continue
+		}
+		matches, err := root.findAll(field.Selector)
+		if err != nil {
+			return nil, nil, err
+		}
+		documentMatches[field.Selector] = matches
+	}
+
+	var results []Result
+	var errs []string
+	for i, item := range items {
+		result, err := parseSelectorItem(item, i, documentMatches, siteConfig)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("第 %d 条: %v", i+1, err))
+			continue
+		}
+		results = append(results, *result)
+	}
+	return results, errs, nil
+}
+
+// parseSelectorItem 从单个条目元素中提取标题、链接和日期
+func parseSelectorItem(item selectorNode, index int, documentMatches map[string][]selectorNode, siteConfig config.SiteConfig) (*Result, error) {
+	rules := siteConfig.Selectors
+
+	// 提取标题
+	titleNode, err := findField(item, index, rules.Title, documentMatches)
+	if err != nil {
+		return nil, fmt.Errorf("未找到标题: %v", err)
+	}
+	title := fieldValue(titleNode, rules.Title)
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("标题为空")
+	}
+
+	// 提取链接，未配置时使用标题元素的链接
+	var relativeURL string
+	if rules.Link.Selector == "" && rules.Link.Attr == "" {
+		href, ok := titleNode.link()
+		if !ok {
+			return nil, fmt.Errorf("未找到链接")
+		}
+		relativeURL = href
+	} else {
+		linkNode, err := findField(item, index, rules.Link, documentMatches)
+		if err != nil {
+			return nil, fmt.Errorf("未找到链接: %v", err)
+		}
+		linkField := rules.Link
+		if linkField.Attr == "" {
+			linkField.Attr = "href"
+		}
+		relativeURL = fieldValue(linkNode, linkField)
+	}
+	if strings.TrimSpace(relativeURL) == "" {
+		return nil, fmt.Errorf("未找到链接")
+	}
+	endpoint, err := resolveURL(relativeURL, siteConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// 提取日期
+	dateNode, err := findField(item, index, rules.Date, documentMatches)
+	if err != nil {
+		return nil, fmt.Errorf("未找到日期元素: %v", err)
+	}
+	date, err := selectorDate(dateNode, rules.Date, siteConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Title:    strings.TrimSpace(title),
+		Endpoint: endpoint,
+		Date:     date,
+	}, nil
+}
+
+// selectorDate 解析日期元素，未指定属性时优先使用 <time datetime="..."> 等元素的 datetime 属性
+func selectorDate(node selectorNode, field config.FieldSelector, siteConfig config.SiteConfig) (time.Time, error) {
+	if field.Attr == "" {
+		if datetime, ok := node.attr("datetime"); ok && datetime != "" {
+			if date, err := parseDate(datetime, siteConfig); err == nil {
+				return date, nil
+			}
+		}
+	}
+	return parseDate(fieldValue(node, field), siteConfig)
+}
+
+// findField 按字段选择器查找对应的元素
+func findField(item selectorNode, index int, field config.FieldSelector, documentMatches map[string][]selectorNode) (selectorNode, error) {
+	if field.Scope == config.ScopeDocument {
+		matches := documentMatches[field.Selector]
+		if index >= len(matches) {
+			return nil, fmt.Errorf("页面中没有与第 %d 条对应的 %s", index+1, field.Selector)
+		}
+		return matches[index], nil
+	}
+
+	matches, err := item.findAll(field.Selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("条目中没有匹配 %s 的元素", field.Selector)
+	}
+	return matches[0], nil
+}
+
+// fieldValue 按字段配置取元素的属性值或文本
+func fieldValue(node selectorNode, field config.FieldSelector) string {
+	if field.Attr != "" {
+		value, _ := node.attr(field.Attr)
+		return value
+	}
+	return node.text()
+}
+
+// newSelectorRoot 按选择器类型解析HTML文档
+func newSelectorRoot(selectorType, htmlContent string) (selectorNode, error) {
+	switch selectorType {
+	case "", config.SelectorCSS:
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+		if err != nil {
+			return nil, fmt.Errorf("HTML解析错误: %v", err)
+		}
+		return cssNode{selection: doc.Selection}, nil
+	case config.SelectorXPath:
+		doc, err := htmlquery.Parse(strings.NewReader(htmlContent))
+		if err != nil {
+			return nil, fmt.Errorf("HTML解析错误: %v", err)
+		}
+		return xpathNode{node: doc}, nil
+	default:
+		return nil, fmt.Errorf("不支持的选择器类型: %s", selectorType)
+	}
+}
+
+// cssNode 是基于 goquery 的 CSS 选择器元素
+type cssNode struct {
+	selection *goquery.Selection
+}
+
+func (n cssNode) findAll(selector string) ([]selectorNode, error) {
+	if selector == "" {
+		return []selectorNode{n}, nil
+	}
+
+	// 先编译选择器，避免非法选择器导致 goquery panic
+	matcher, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("CSS 选择器 %q 无效: %v", selector, err)
+	}
+
+	var nodes []selectorNode
+	n.selection.FindMatcher(matcher).Each(func(_ int, s *goquery.Selection) {
+		nodes = append(nodes, cssNode{selection: s})
+	})
+	return nodes, nil
+}
+
+func (n cssNode) text() string {
+	return n.selection.Text()
+}
+
+func (n cssNode) attr(name string) (string, bool) {
+	return n.selection.Attr(name)
+}
+
+func (n cssNode) link() (string, bool) {
+	if href, ok := n.selection.Attr("href"); ok && href != "" {
+		return href, true
+	}
+	href, ok := n.selection.Find("a[href]").First().Attr("href")
+	return href, ok && href != ""
+}
+
+// xpathNode 是基于 htmlquery 的 XPath 元素
+type xpathNode struct {
+	node *html.Node
+}
+
+func (n xpathNode) findAll(selector string) ([]selectorNode, error) {
+	if selector == "" {
+		return []selectorNode{n}, nil
+	}
+
+	matches, err := htmlquery.QueryAll(n.node, selector)
+	if err != nil {
+		return nil, fmt.Errorf("XPath %q 无效: %v", selector, err)
+	}
+
+	nodes := make([]selectorNode, 0, len(matches))
+	for _, match := range matches {
+		nodes = append(nodes, xpathNode{node: match})
+	}
+	return nodes, nil
+}
+
+func (n xpathNode) text() string {
+	return htmlquery.InnerText(n.node)
+}
+
+func (n xpathNode) attr(name string) (string, bool) {
+	for _, attr := range n.node.Attr {
+		if attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func (n xpathNode) link() (string, bool) {
+	if href, ok := n.attr("href"); ok && href != "" {
+		return href, true
+	}
+	anchor := htmlquery.FindOne(n.node, ".//a[@href]")
+	if anchor == nil {
+		return "", false
+	}
+	return xpathNode{node: anchor}.attr("href")
+}
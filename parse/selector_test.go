@@ -0,0 +1,146 @@
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"code/config"
+)
+
+const selectorPage = `<html><body>
+<ul class="news">
+	<li class="item"><a href="/news/1">First</a><time datetime="2024-05-02T08:00:00Z">May 2</time></li>
+	<li class="item"><h3 data-url="https://other.example.com/2">Second</h3><span class="date">2024-05-01</span></li>
+	<li class="item"><span class="date">2024-04-30</span></li>
+</ul>
+<div class="dates"><em>2024-05-04</em><em>2024-05-03</em></div>
+</body></html>`
+
+func TestParseSelectorItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors config.SelectorRules
+		want      []Result
+		wantErrs  int
+	}{
+		{
+			name: "CSS 默认从标题元素取链接",
+			selectors: config.SelectorRules{
+				Item:  "li.item",
+				Title: config.FieldSelector{Selector: "a"},
+				Date:  config.FieldSelector{Selector: "time"},
+			},
+			want: []Result{
+				{Title: "First", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)},
+			},
+			wantErrs: 2,
+		},
+		{
+			name: "CSS 指定属性取链接",
+			selectors: config.SelectorRules{
+				Item:  "li.item",
+				Title: config.FieldSelector{Selector: "h3"},
+				Link:  config.FieldSelector{Selector: "h3", Attr: "data-url"},
+				Date:  config.FieldSelector{Selector: ".date"},
+			},
+			want: []Result{
+				{Title: "Second", Endpoint: "https://other.example.com/2", Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			wantErrs: 2,
+		},
+		{
+			name: "XPath 条目内链接",
+			selectors: config.SelectorRules{
+				Type:  config.SelectorXPath,
+				Item:  "//li[@class='item']",
+				Title: config.FieldSelector{Selector: ".//a"},
+				Link:  config.FieldSelector{Selector: ".//a", Attr: "href"},
+				Date:  config.FieldSelector{Selector: ".//time", Attr: "datetime"},
+			},
+			want: []Result{
+				{Title: "First", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)},
+			},
+			wantErrs: 2,
+		},
+		{
+			name: "XPath 默认从条目元素中查找链接",
+			selectors: config.SelectorRules{
+				Type:  config.SelectorXPath,
+				Item:  "//li[@class='item'][a]",
+				Title: config.FieldSelector{},
+				Date:  config.FieldSelector{Selector: ".//time"},
+			},
+			want: []Result{
+				{Title: "FirstMay 2", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "document 范围的日期按顺序与条目对应",
+			selectors: config.SelectorRules{
+				Item:  "li.item",
+				Title: config.FieldSelector{},
+				Link:  config.FieldSelector{Selector: "a"},
+				Date:  config.FieldSelector{Selector: ".dates em", Scope: config.ScopeDocument},
+			},
+			want: []Result{
+				{Title: "FirstMay 2", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC)},
+			},
+			wantErrs: 2,
+		},
+		{
+			name: "document 范围的标题多于条目时多余的忽略",
+			selectors: config.SelectorRules{
+				Type:  config.SelectorXPath,
+				Item:  "//div[@class='dates']",
+				Title: config.FieldSelector{Selector: "//ul//a", Scope: config.ScopeDocument},
+				Date:  config.FieldSelector{Selector: "//li//time", Scope: config.ScopeDocument},
+			},
+			want: []Result{
+				{Title: "First", Endpoint: "https://example.com/news/1", Date: time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectors := tt.selectors
+			site := config.SiteConfig{Name: "selectors", BaseURL: "https://example.com", Selectors: &selectors}
+
+			results, errs, err := parseSelectorItems(selectorPage, site)
+			if err != nil {
+				t.Fatalf("parseSelectorItems: %v", err)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("单条失败 %d 条 (%v), 期望 %d 条", len(errs), errs, tt.wantErrs)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("解析出 %d 条, 期望 %d 条: %+v", len(results), len(tt.want), results)
+			}
+			for i := range tt.want {
+				if results[i].Title != tt.want[i].Title || results[i].Endpoint != tt.want[i].Endpoint || !results[i].Date.Equal(tt.want[i].Date) {
+					t.Errorf("第 %d 条 = %+v, 期望 %+v", i+1, results[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSelectorItemsInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors config.SelectorRules
+	}{
+		{"没有匹配的条目", config.SelectorRules{Item: "li.missing"}},
+		{"CSS 选择器无效", config.SelectorRules{Item: "li[["}},
+		{"XPath 无效", config.SelectorRules{Type: config.SelectorXPath, Item: "//li[@class="}},
+		{"选择器类型不支持", config.SelectorRules{Type: "jquery", Item: "li"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectors := tt.selectors
+			site := config.SiteConfig{BaseURL: "https://example.com", Selectors: &selectors}
+			if _, _, err := parseSelectorItems(selectorPage, site); err == nil {
+				t.Error("应返回错误")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package route
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code/config"
+	"code/filter"
+	"code/parse"
+)
+
+// Decision 是条目的路由结果
+type Decision struct {
+	Destinations []string // 推送目的地名称（去重并排序）
+	Keywords     []string // 标题中命中过滤规则或路由关键词的文本，用于高亮
+	Filtered     bool     // 条目未通过站点的过滤规则
+}
+
+// rule 是编译后的路由规则，keywords 作为过滤规则的包含条件
+type rule struct {
+	config.RouteConfig
+	filter *filter.Filter
+}
+
+// Router 按站点过滤规则和路由规则为条目选择推送目的地
+type Router struct {
+	rules   []rule
+	sites   map[string]*filter.Filter // 站点名称到站点过滤规则
+	all     []string                  // 未配置路由规则时使用的全部目的地
+	enabled bool                      // 是否配置了路由规则
+}
+
+// NewRouter 编译站点过滤规则和路由规则，未配置路由规则时所有条目推送到全部目的地
+func NewRouter(cfg *config.Config) (*Router, error) {
+	r := &Router{
+		sites:   make(map[string]*filter.Filter, len(cfg.Sites)),
+		enabled: len(cfg.Routes) > 0,
+	}
+	for _, destination := range cfg.Destinations {
+		r.all = append(r.all, destination.Name)
+	}
+	sort.Strings(r.all)
+
+	for _, site := range cfg.Sites {
+		f, err := filter.New(site.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("站点 %s 的过滤规则无效: %v", site.Name, err)
+		}
+		r.sites[site.Name] = f
+	}
+
+	for _, route := range cfg.Routes {
+		filters := route.Filters
+		filters.Include = append(append([]string(nil), filters.Include...), route.Keywords...)
+		f, err := filter.New(filters)
+		if err != nil {
+			return nil, fmt.Errorf("路由 %s 的过滤规则无效: %v", route.Name, err)
+		}
+		r.rules = append(r.rules, rule{RouteConfig: route, filter: f})
+	}
+	return r, nil
+}
+
+// Route 先按站点过滤规则检查条目，再返回所有匹配的路由规则的目的地；没有匹配的规则时目的地为空
+func (r *Router) Route(site config.SiteConfig, result parse.Result) Decision {
+	titles := []string{result.OriginalTitle, result.TranslatedTitle}
+
+	var decision Decision
+	if f, ok := r.sites[site.Name]; ok {
+		passed, keywords := f.Match(titles...)
+		if !passed {
+			return Decision{Filtered: true}
+		}
+		decision.Keywords = keywords
+	}
+
+	if !r.enabled {
+		decision.Destinations = r.all
+		return decision
+	}
+
+	matched := make(map[string]bool)
+	for _, rule := range r.rules {
+		if len(rule.Sites) > 0 && !containsFold(rule.Sites, site.Name) {
+			continue
+		}
+		if len(rule.Tags) > 0 && !matchTags(rule.Tags, site) {
+			continue
+		}
+		passed, keywords := rule.filter.Match(titles...)
+		if !passed {
+			continue
+		}
+		decision.Keywords = append(decision.Keywords, keywords...)
+		for _, name := range rule.Destinations {
+			matched[name] = true
+		}
+	}
+
+	for name := range matched {
+		decision.Destinations = append(decision.Destinations, name)
+	}
+	sort.Strings(decision.Destinations)
+	decision.Keywords = filter.Dedup(decision.Keywords)
+	return decision
+}
+
+// matchTags 判断站点的标签或分类是否在规则的标签中
+func matchTags(tags []string, site config.SiteConfig) bool {
+	if site.Category != "" && containsFold(tags, site.Category) {
+		return true
+	}
+	for _, tag := range site.Tags {
+		if containsFold(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold 判断 values 中是否有与 value 相同的值，不区分大小写
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"code/config"
+
+	"github.com/robfig/cron/v3"
+)
+
+// entry 是单个站点的调度状态
+type entry struct {
+	site config.SiteConfig
+	cron cron.Schedule // 配置了 cron 时使用，否则按 site.Interval 间隔执行
+	next time.Time
+}
+
+// Scheduler 按站点各自的 interval 或 cron 调度抓取，每次执行时间加上随机延迟
+type Scheduler struct {
+	entries []*entry
+	loc     *time.Location
+	run     func(sites []config.SiteConfig) // 执行到期的站点，由调用方保证同一站点不会重叠执行
+	wg      sync.WaitGroup                  // 正在执行的 run
+}
+
+// New 创建调度器，cron 表达式按 loc 时区解释；所有站点在启动时立即执行一次
+func New(sites []config.SiteConfig, loc *time.Location, run func(sites []config.SiteConfig)) (*Scheduler, error) {
+	now := time.Now()
+
+	s := &Scheduler{loc: loc, run: run}
+	for _, site := range sites {
+		e := &entry{site: site, next: now}
+		if site.Cron != "" {
+			schedule, err := cron.ParseStandard(site.Cron)
+			if err != nil {
+				return nil, err
+			}
+			e.cron = schedule
+		}
+		s.entries = append(s.entries, e)
+	}
+	return s, nil
+}
+
+// Run 循环等待下一个到期的站点并在后台执行，ctx 取消后停止调度并返回，已开始的执行不受影响
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		now := time.Now()
+		var due []config.SiteConfig
+		for _, e := range s.entries {
+			if e.next.After(now) {
+				continue
+			}
+			due = append(due, e.site)
+			e.next = s.nextRun(e, now)
+			if e.cron != nil {
+				log.Printf("站点 %s 下次抓取时间: %s\n", e.site.Name, e.next.In(s.loc).Format("2006-01-02 15:04:05"))
+			}
+		}
+		if len(due) > 0 {
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.run(due)
+			}()
+		}
+
+		timer := time.NewTimer(time.Until(s.earliest()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Wait 等待所有已开始的执行结束
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// nextRun 计算站点的下次执行时间：cron 站点取表达式的下一个时间点，其余站点按间隔，再加上随机延迟
+func (s *Scheduler) nextRun(e *entry, now time.Time) time.Time {
+	var next time.Time
+	if e.cron != nil {
+		next = e.cron.Next(now.In(s.loc))
+	} else {
+		next = now.Add(e.site.Interval)
+	}
+	if e.site.Jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(e.site.Jitter))))
+	}
+	return next
+}
+
+// earliest 返回最早的下次执行时间
+func (s *Scheduler) earliest() time.Time {
+	if len(s.entries) == 0 {
+		return time.Now().Add(time.Minute)
+	}
+	earliest := s.entries[0].next
+	for _, e := range s.entries[1:] {
+		if e.next.Before(earliest) {
+			earliest = e.next
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"code/config"
+)
+
+func TestNextRun(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	now := time.Date(2024, 5, 10, 8, 20, 30, 0, time.UTC) // 上海时间 16:20:30
+
+	tests := []struct {
+		name string
+		site config.SiteConfig
+		want time.Time
+	}{
+		{"按间隔", config.SiteConfig{Interval: 2 * time.Minute}, now.Add(2 * time.Minute)},
+		{"每 15 分钟", config.SiteConfig{Cron: "*/15 * * * *"}, time.Date(2024, 5, 10, 16, 30, 0, 0, shanghai)},
+		{"cron 按全局时区解释", config.SiteConfig{Cron: "0 9 * * *"}, time.Date(2024, 5, 11, 9, 0, 0, 0, shanghai)},
+		{"工作日", config.SiteConfig{Cron: "30 8 * * 1-5"}, time.Date(2024, 5, 13, 8, 30, 0, 0, shanghai)},
+		{"cron 优先于间隔", config.SiteConfig{Cron: "0 17 * * *", Interval: time.Minute}, time.Date(2024, 5, 10, 17, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New([]config.SiteConfig{tt.site}, shanghai, nil)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if got := s.nextRun(s.entries[0], now); !got.Equal(tt.want) {
+				t.Errorf("nextRun = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextRunJitter(t *testing.T) {
+	now := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		site config.SiteConfig
+		base time.Time
+	}{
+		{"间隔加随机延迟", config.SiteConfig{Interval: 5 * time.Minute, Jitter: 30 * time.Second}, now.Add(5 * time.Minute)},
+		{"cron 加随机延迟", config.SiteConfig{Cron: "0 * * * *", Jitter: 10 * time.Second}, now.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New([]config.SiteConfig{tt.site}, time.UTC, nil)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			for i := 0; i < 100; i++ {
+				got := s.nextRun(s.entries[0], now)
+				if got.Before(tt.base) || !got.Before(tt.base.Add(tt.site.Jitter)) {
+					t.Fatalf("nextRun = %s, 期望在 [%s, %s) 范围内", got, tt.base, tt.base.Add(tt.site.Jitter))
+				}
+			}
+		})
+	}
+}
+
+func TestNewInvalidCron(t *testing.T) {
+	if _, err := New([]config.SiteConfig{{Name: "bad", Cron: "every minute"}}, time.UTC, nil); err == nil {
+		t.Error("cron 表达式无效时应返回错误")
+	}
+}
+
+func TestRunStartsAllSitesImmediately(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	s, err := New([]config.SiteConfig{{Name: "a", Interval: time.Hour}, {Name: "b", Cron: "0 0 1 1 *"}}, time.UTC, func(sites []config.SiteConfig) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, site := range sites {
+			ran = append(ran, site.Name)
+		}
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+	s.Wait()
+
+	if len(ran) != 2 {
+		t.Errorf("启动时执行了 %v, 期望所有站点各执行一次", ran)
+	}
+}
@@ -0,0 +1,65 @@
+package translate
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Cache 是翻译缓存使用的键值存储，db.DatabaseClient 满足该接口
+type Cache interface {
+	GetKey(ctx context.Context, key string) (string, error)
+	SetKeyWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error
+}
+
+// Cached 为其他 Translator 增加缓存，以翻译服务、源语言、目标语言和原文哈希为键，避免重复调用翻译服务
+type Cached struct {
+	next     Translator
+	provider string // 翻译服务名称，切换翻译服务后不会读到其他服务的译文
+	cache    Cache
+	ttl      time.Duration
+}
+
+// NewCached 创建带缓存的 Translator，provider 为下层翻译服务的名称
+func NewCached(next Translator, provider string, cache Cache, ttl time.Duration) *Cached {
+	if provider == "" {
+		provider = ProviderTencent
+	}
+	return &Cached{
+		next:     next,
+		provider: provider,
+		cache:    cache,
+		ttl:      ttl,
+	}
+}
+
+// Translate 优先返回缓存中的译文，未命中时调用下层翻译服务并写入缓存
+func (c *Cached) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	key := cacheKey(c.provider, text, sourceLang, targetLang)
+	if translated, err := c.cache.GetKey(ctx, key); err == nil {
+		return translated, nil
+	}
+
+	translated, err := c.next.Translate(ctx, text, sourceLang, targetLang)
+	if err != nil {
+		return "", err
+	}
+
+	// 缓存写入失败不影响翻译结果
+	if err := c.cache.SetKeyWithTTL(ctx, key, translated, c.ttl); err != nil {
+		log.Printf("写入翻译缓存失败: %v", err)
+	}
+	return translated, nil
+}
+
+// cacheKey 返回翻译缓存的键名，未指定源语言时记为 auto
+func cacheKey(provider, text, sourceLang, targetLang string) string {
+	if sourceLang == "" {
+		sourceLang = "auto"
+	}
+	sum := sha1.Sum([]byte(text))
+	return fmt.Sprintf("translate:%s:%s:%s:%s", provider, sourceLang, targetLang, hex.EncodeToString(sum[:]))
+}
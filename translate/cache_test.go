@@ -0,0 +1,85 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryCache 是测试用的内存缓存
+type memoryCache map[string]string
+
+func (m memoryCache) GetKey(ctx context.Context, key string) (string, error) {
+	value, ok := m[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func (m memoryCache) SetKeyWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+// countingTranslator 记录调用次数，返回带前缀的译文
+type countingTranslator struct {
+	prefix string
+	calls  int
+}
+
+func (c *countingTranslator) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	c.calls++
+	return c.prefix + text, nil
+}
+
+func TestCachedReusesTranslation(t *testing.T) {
+	cache := memoryCache{}
+	next := &countingTranslator{prefix: "译:"}
+	cached := NewCached(next, ProviderTencent, cache, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		got, err := cached.Translate(context.Background(), "hello", "", "zh")
+		if err != nil || got != "译:hello" {
+			t.Fatalf("Translate = %q, %v", got, err)
+		}
+	}
+	if next.calls != 1 {
+		t.Errorf("翻译服务被调用 %d 次, 期望 1 次", next.calls)
+	}
+
+	if _, err := cached.Translate(context.Background(), "hello", "", "en"); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if next.calls != 2 {
+		t.Errorf("目标语言不同时应重新翻译, 调用 %d 次", next.calls)
+	}
+}
+
+func TestCachedKeyIncludesProvider(t *testing.T) {
+	cache := memoryCache{}
+	NewCached(&countingTranslator{prefix: "other:"}, "other", cache, time.Hour).Translate(context.Background(), "hello", "", "zh")
+
+	tencent := &countingTranslator{prefix: "译:"}
+	got, err := NewCached(tencent, "", cache, time.Hour).Translate(context.Background(), "hello", "", "zh")
+	if err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+	if got != "译:hello" || tencent.calls != 1 {
+		t.Errorf("Translate = %q (调用 %d 次), 不应读到其他翻译服务的缓存", got, tencent.calls)
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for provider, want := range map[string]bool{
+		"":                 true,
+		ProviderTencent:    true,
+		ProviderDictionary: false,
+		ProviderNoop:       false,
+	} {
+		if got := IsRemote(provider); got != want {
+			t.Errorf("IsRemote(%q) = %v, 期望 %v", provider, got, want)
+		}
+	}
+}
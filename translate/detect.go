@@ -0,0 +1,67 @@
+package translate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DetectLanguage 根据字符所属的文字系统粗略判断文本语言，返回 zh、ja、ko、ru、ar、th、en，无法判断时返回空字符串
+// 拉丁字母的文本统一视为 en，只用于判断是否需要翻译
+func DetectLanguage(text string) string {
+	var han, kana, hangul, latin, cyrillic, arabic, thai int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Thai, r):
+			thai++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch {
+	case kana > 0:
+		return "ja"
+	case hangul > 0 && hangul >= han:
+		return "ko"
+	// 中文标题中常夹杂英文品牌名，一个汉字大致相当于一个英文单词
+	case han > 0 && han*3 >= latin:
+		return "zh"
+	case cyrillic > latin:
+		return "ru"
+	case arabic > latin:
+		return "ar"
+	case thai > latin:
+		return "th"
+	case latin > 0:
+		return "en"
+	default:
+		return ""
+	}
+}
+
+// SameLanguage 判断两个语言代码是否为同一种语言，忽略地区后缀（如 zh-CN 与 zh）
+func SameLanguage(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return baseLanguage(a) == baseLanguage(b)
+}
+
+// baseLanguage 返回语言代码中的主语言部分
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		lang = lang[:i]
+	}
+	return lang
+}
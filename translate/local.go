@@ -0,0 +1,22 @@
+package translate
+
+import "context"
+
+// Noop 不做任何翻译，原样返回文本
+type Noop struct{}
+
+// Translate 原样返回文本
+func (Noop) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	return text, nil
+}
+
+// Dictionary 是基于固定词典的 Translator 实现，按原文整句查找译文，找不到时原样返回
+type Dictionary map[string]string
+
+// Translate 在词典中查找译文
+func (d Dictionary) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	if translated, ok := d[text]; ok {
+		return translated, nil
+	}
+	return text, nil
+}
@@ -0,0 +1,58 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	tmt "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tmt/v20180321"
+
+	"code/config"
+)
+
+// Tencent 是基于腾讯云机器翻译 TMT 的 Translator 实现
+type Tencent struct {
+	client *tmt.Client
+}
+
+// NewTencent 创建并配置腾讯云翻译客户端
+func NewTencent(params config.TencentParamsConfig) (*Tencent, error) {
+	if params.SecretID == "" || params.SecretKey == "" {
+		return nil, fmt.Errorf("缺少腾讯云翻译的 secret_id 或 secret_key")
+	}
+
+	credential := common.NewCredential(
+		params.SecretID,
+		params.SecretKey,
+	)
+
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Endpoint = "tmt.tencentcloudapi.com" // 腾讯云翻译API端点
+
+	client, err := tmt.NewClient(credential, "ap-guangzhou", cpf)
+	if err != nil {
+		return nil, fmt.Errorf("创建 TMT 客户端失败: %v", err)
+	}
+	return &Tencent{client: client}, nil
+}
+
+// Translate 调用腾讯云翻译API，将文本翻译成目标语言，未指定源语言时使用 auto 自动识别
+func (t *Tencent) Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error) {
+	if sourceLang == "" {
+		sourceLang = "auto"
+	}
+	request := tmt.NewTextTranslateRequest()
+	request.SourceText = common.StringPtr(text)
+	request.Source = common.StringPtr(sourceLang)
+	request.Target = common.StringPtr(targetLang)
+	request.ProjectId = common.Int64Ptr(0) // 默认项目ID
+
+	response, err := t.client.TextTranslateWithContext(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("翻译请求失败: %v", err)
+	}
+
+	// 返回翻译后的文本
+	return *response.Response.TargetText, nil
+}
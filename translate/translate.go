@@ -0,0 +1,40 @@
+package translate
+
+import (
+	"context"
+	"fmt"
+
+	"code/config"
+)
+
+// Translator 是通用的翻译接口
+type Translator interface {
+	// Translate 将文本从 sourceLang 翻译为目标语言，sourceLang 为空时由翻译服务自动识别；ctx 取消时放弃翻译，失败时返回错误
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error)
+}
+
+// 支持的翻译服务
+const (
+	ProviderTencent    = "tencent"    // 腾讯云机器翻译 TMT
+	ProviderDictionary = "dictionary" // 本地词典，用于测试和离线运行
+	ProviderNoop       = "noop"       // 不翻译，原样返回
+)
+
+// IsRemote 返回翻译服务是否需要调用远程接口；本地翻译不需要缓存
+func IsRemote(provider string) bool {
+	return provider == "" || provider == ProviderTencent
+}
+
+// New 根据配置创建相应的 Translator 实现
+func New(cfg config.TranslateConfig, tencentParams config.TencentParamsConfig) (Translator, error) {
+	switch cfg.Provider {
+	case "", ProviderTencent:
+		return NewTencent(tencentParams)
+	case ProviderDictionary:
+		return Dictionary(cfg.Dictionary), nil
+	case ProviderNoop:
+		return Noop{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的翻译服务: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,81 @@
+package translate
+
+import (
+	"context"
+	"testing"
+
+	"code/config"
+)
+
+func TestNoop(t *testing.T) {
+	for _, text := range []string{"", "NVIDIA announces new GPU", "英伟达发布新 GPU"} {
+		got, err := Noop{}.Translate(context.Background(), text, "", "zh")
+		if err != nil {
+			t.Fatalf("Translate(%q): %v", text, err)
+		}
+		if got != text {
+			t.Errorf("Translate(%q) = %q, 应原样返回", text, got)
+		}
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	dictionary := Dictionary{
+		"NVIDIA announces new GPU": "英伟达发布新 GPU",
+		"Rate decision":            "利率决议",
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"NVIDIA announces new GPU", "英伟达发布新 GPU"},
+		{"Rate decision", "利率决议"},
+		// 按整句查找，不做部分匹配或大小写转换
+		{"rate decision", "rate decision"},
+		{"NVIDIA announces new GPU today", "NVIDIA announces new GPU today"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := dictionary.Translate(context.Background(), tt.text, "", "zh")
+		if err != nil {
+			t.Fatalf("Translate(%q): %v", tt.text, err)
+		}
+		if got != tt.want {
+			t.Errorf("Translate(%q) = %q, 期望 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNilDictionary(t *testing.T) {
+	got, err := Dictionary(nil).Translate(context.Background(), "text", "", "zh")
+	if err != nil || got != "text" {
+		t.Errorf("Translate = %q, %v, 空词典应原样返回", got, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	cfg := config.TranslateConfig{Dictionary: map[string]string{"a": "甲"}}
+
+	cfg.Provider = ProviderNoop
+	translator, err := New(cfg, config.TencentParamsConfig{})
+	if err != nil {
+		t.Fatalf("New(noop): %v", err)
+	}
+	if _, ok := translator.(Noop); !ok {
+		t.Errorf("New(noop) = %T, 期望 Noop", translator)
+	}
+
+	cfg.Provider = ProviderDictionary
+	translator, err = New(cfg, config.TencentParamsConfig{})
+	if err != nil {
+		t.Fatalf("New(dictionary): %v", err)
+	}
+	if got, _ := translator.Translate(context.Background(), "a", "", "zh"); got != "甲" {
+		t.Errorf("dictionary 翻译服务应使用配置中的词典, 得到 %q", got)
+	}
+
+	cfg.Provider = "google"
+	if _, err := New(cfg, config.TencentParamsConfig{}); err == nil {
+		t.Error("不支持的翻译服务应返回错误")
+	}
+}
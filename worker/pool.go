@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Task 是一个待执行的任务
+type Task struct {
+	Key    string // 任务标识，同一标识的任务不会同时执行
+	Domain string // 任务访问的域名，同一域名的任务串行执行
+	Run    func()
+}
+
+// Pool 是有并发上限的任务池，同一域名的任务串行执行，避免对同一网站并发请求
+type Pool struct {
+	slots chan struct{} // 并发执行的名额，多次 RunAll 共享
+
+	mu      sync.Mutex
+	domains map[string]*sync.Mutex // 域名到串行锁
+	running map[string]bool        // 正在执行的任务标识，包括上一轮超时后仍在执行的任务
+}
+
+// NewPool 创建任务池，concurrency 为同时执行的任务数上限
+func NewPool(concurrency int) *Pool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &Pool{
+		slots:   make(chan struct{}, concurrency),
+		domains: make(map[string]*sync.Mutex),
+		running: make(map[string]bool),
+	}
+}
+
+// RunAll 执行任务并等待全部完成，超过 timeout 时不再等待并返回仍未完成的任务标识；
+// 超时的任务在后台继续执行，仍在执行的任务不会重复执行。多个 RunAll 可以同时调用，共享并发上限
+func (p *Pool) RunAll(tasks []Task, timeout time.Duration) []string {
+	// 先标记本轮要执行的任务，超时后未开始的任务也计入未完成
+	var runnable []Task
+	for _, task := range tasks {
+		if !p.start(task.Key) {
+			log.Printf("任务 %s 上一轮仍在执行, 本轮跳过\n", task.Key)
+			continue
+		}
+		runnable = append(runnable, task)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(runnable))
+	for _, task := range runnable {
+		go func() {
+			defer wg.Done()
+			p.run(task)
+		}()
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-deadline.C:
+		return p.pending(tasks)
+	}
+}
+
+// start 将任务标记为正在执行，任务已在执行时返回 false
+func (p *Pool) start(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running[key] {
+		return false
+	}
+	p.running[key] = true
+	return true
+}
+
+// run 先取得域名锁再占用并发名额执行任务，等待同一域名的任务不占用名额；结束后清除执行标记
+func (p *Pool) run(task Task) {
+	lock := p.domainLock(task.Domain)
+	lock.Lock()
+	p.slots <- struct{}{}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("任务 %s 异常退出: %v\n", task.Key, r)
+		}
+		<-p.slots
+		lock.Unlock()
+		p.mu.Lock()
+		delete(p.running, task.Key)
+		p.mu.Unlock()
+	}()
+	task.Run()
+}
+
+// domainLock 返回域名对应的串行锁
+func (p *Pool) domainLock(domain string) *sync.Mutex {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lock, ok := p.domains[domain]
+	if !ok {
+		lock = &sync.Mutex{}
+		p.domains[domain] = lock
+	}
+	return lock
+}
+
+// pending 返回仍在执行或等待执行的任务标识
+func (p *Pool) pending(tasks []Task) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var keys []string
+	for _, task := range tasks {
+		if p.running[task.Key] {
+			keys = append(keys, task.Key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunAll(t *testing.T) {
+	pool := NewPool(4)
+	var ran atomic.Int32
+	tasks := []Task{
+		{Key: "a", Domain: "a.com", Run: func() { ran.Add(1) }},
+		{Key: "b", Domain: "b.com", Run: func() { ran.Add(1) }},
+		{Key: "c", Domain: "a.com", Run: func() { ran.Add(1) }},
+	}
+	if pending := pool.RunAll(tasks, time.Second); pending != nil {
+		t.Errorf("RunAll = %v, 期望全部完成", pending)
+	}
+	if ran.Load() != 3 {
+		t.Errorf("执行了 %d 个任务, 期望 3 个", ran.Load())
+	}
+}
+
+func TestRunAllDeadline(t *testing.T) {
+	pool := NewPool(4)
+	release := make(chan struct{})
+	defer close(release)
+
+	tasks := []Task{
+		{Key: "fast", Domain: "a.com", Run: func() {}},
+		{Key: "slow", Domain: "b.com", Run: func() { <-release }},
+		// 与 slow 同一域名，排在 slow 之后等待
+		{Key: "queued", Domain: "b.com", Run: func() {}},
+	}
+	start := time.Now()
+	pending := pool.RunAll(tasks, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RunAll 用时 %s, 应在超时后返回", elapsed)
+	}
+	slices.Sort(pending)
+	// queued 可能在 slow 之前取得域名锁，此时只有 slow 未完成
+	if !slices.Equal(pending, []string{"queued", "slow"}) && !slices.Equal(pending, []string{"slow"}) {
+		t.Errorf("RunAll = %v, 期望返回未完成的任务", pending)
+	}
+}
+
+func TestRunAllSkipsRunningKey(t *testing.T) {
+	pool := NewPool(4)
+	release := make(chan struct{})
+	var runs atomic.Int32
+	slow := Task{Key: "site", Domain: "a.com", Run: func() {
+		runs.Add(1)
+		<-release
+	}}
+
+	if pending := pool.RunAll([]Task{slow}, 20*time.Millisecond); !slices.Equal(pending, []string{"site"}) {
+		t.Fatalf("RunAll = %v, 期望 site 超时", pending)
+	}
+	// 上一轮超时的任务仍在执行，本轮跳过，不等待它完成
+	start := time.Now()
+	if pending := pool.RunAll([]Task{slow}, time.Second); pending != nil {
+		t.Errorf("RunAll = %v, 跳过的任务不应计入本轮未完成", pending)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("RunAll 用时 %s, 不应等待上一轮的任务", elapsed)
+	}
+	if runs.Load() != 1 {
+		t.Errorf("任务执行了 %d 次, 仍在执行时不应重复执行", runs.Load())
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if pending := pool.RunAll([]Task{{Key: "site", Domain: "a.com", Run: func() { runs.Add(1) }}}, time.Second); pending == nil && runs.Load() == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("上一轮结束后任务应能再次执行, 执行了 %d 次", runs.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRunAllLimits(t *testing.T) {
+	pool := NewPool(2)
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	domainRunning := make(map[string]int)
+	task := func(key, domain string) Task {
+		return Task{Key: key, Domain: domain, Run: func() {
+			mu.Lock()
+			running++
+			domainRunning[domain]++
+			maxRunning = max(maxRunning, running)
+			if domainRunning[domain] > 1 {
+				t.Errorf("域名 %s 有多个任务同时执行", domain)
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			domainRunning[domain]--
+			mu.Unlock()
+		}}
+	}
+
+	tasks := []Task{task("a1", "a.com"), task("a2", "a.com"), task("b1", "b.com"), task("c1", "c.com"), task("d1", "d.com")}
+	if pending := pool.RunAll(tasks, time.Second); pending != nil {
+		t.Fatalf("RunAll = %v, 期望全部完成", pending)
+	}
+	if maxRunning > 2 {
+		t.Errorf("最多同时执行 %d 个任务, 超过并发上限 2", maxRunning)
+	}
+}
+
+func TestRunAllRecoversPanic(t *testing.T) {
+	pool := NewPool(1)
+	tasks := []Task{
+		{Key: "panic", Domain: "a.com", Run: func() { panic("boom") }},
+		{Key: "next", Domain: "a.com", Run: func() {}},
+	}
+	if pending := pool.RunAll(tasks, time.Second); pending != nil {
+		t.Errorf("RunAll = %v, 任务异常退出后应释放名额和域名锁", pending)
+	}
+}